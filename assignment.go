@@ -0,0 +1,48 @@
+package gen
+
+import (
+	"fmt"
+
+	"gorm.io/gen/field"
+	"gorm.io/gorm/clause"
+)
+
+// AssignmentList assignments of an UPDATE SET list, pass it to Updates or UpdateColumns
+type AssignmentList []field.AssignExpr
+
+// Assignments compose assignments into an AssignmentList
+func Assignments(exprs ...field.AssignExpr) AssignmentList {
+	return append(AssignmentList{}, exprs...)
+}
+
+// Add append assignments to list
+func (l AssignmentList) Add(exprs ...field.AssignExpr) AssignmentList {
+	return append(l, exprs...)
+}
+
+// validate check no column is assigned more than once
+func (l AssignmentList) validate() error {
+	seen := make(map[string]bool, len(l))
+	check := func(name string) error {
+		if seen[name] {
+			return fmt.Errorf("%w: %s", ErrDuplicateAssignment, name)
+		}
+		seen[name] = true
+		return nil
+	}
+
+	for _, expr := range l {
+		if set, ok := expr.AssignExpr().(clause.Set); ok {
+			for _, assignment := range set {
+				if err := check(assignment.Column.Name); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		if err := check(string(expr.ColumnName())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
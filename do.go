@@ -5,7 +5,10 @@ import (
 	"database/sql"
 	"fmt"
 	"reflect"
+	"regexp"
+	"sort"
 	"strings"
+	"time"
 
 	"gorm.io/gorm"
 	"gorm.io/gorm/callbacks"
@@ -33,6 +36,9 @@ type DO struct {
 	modelType reflect.Type
 	tableName string
 
+	// selects columns of Select or Distinct, read by ProjectedColumns
+	selects []field.Expr
+
 	backfillData interface{}
 }
 
@@ -197,7 +203,7 @@ func (*DO) Columns(cols ...field.Expr) Columns { return cols }
 
 // Not ...
 func (d *DO) Not(conds ...Condition) Dao {
-	exprs, err := condToExpression(conds)
+	exprs, err := d.condToExpression(conds)
 	if err != nil {
 		return d.withError(err)
 	}
@@ -209,7 +215,7 @@ func (d *DO) Not(conds ...Condition) Dao {
 
 // Or ...
 func (d *DO) Or(conds ...Condition) Dao {
-	exprs, err := condToExpression(conds)
+	exprs, err := d.condToExpression(conds)
 	if err != nil {
 		return d.withError(err)
 	}
@@ -222,15 +228,29 @@ func (d *DO) Or(conds ...Condition) Dao {
 // Select ...
 func (d *DO) Select(columns ...field.Expr) Dao {
 	if len(columns) == 0 {
-		return d.getInstance(d.db.Clauses(clause.Select{}))
+		return d.withSelects(d.db.Clauses(clause.Select{}), nil)
+	}
+	if d.strictWindowColumns() {
+		for _, column := range columns {
+			if err := field.CheckWindowColumns(column, d.db.Statement.Schema); err != nil {
+				return d.withError(err)
+			}
+		}
 	}
 	query, args := buildExpr4Select(d.db.Statement, columns...)
-	return d.getInstance(d.db.Select(query, args...))
+	return d.withSelects(d.db.Select(query, args...), columns)
+}
+
+// withSelects return instance of db selecting columns, nil columns if they are not field expressions
+func (d *DO) withSelects(db *gorm.DB, columns []field.Expr) *DO {
+	do := d.getInstance(db)
+	do.selects = columns
+	return do
 }
 
 // Where ...
 func (d *DO) Where(conds ...Condition) Dao {
-	exprs, err := condToExpression(conds)
+	exprs, err := d.condToExpression(conds)
 	if err != nil {
 		return d.withError(err)
 	}
@@ -240,6 +260,86 @@ func (d *DO) Where(conds ...Condition) Dao {
 	return d.getInstance(d.db.Clauses(clause.Where{Exprs: exprs}))
 }
 
+// WhereFromStruct add an equality condition for every non-nil field of filters which is mapped to a column,
+// mapping keys are the struct field names, unmapped fields are ignored
+func (d *DO) WhereFromStruct(filters interface{}, mapping map[string]field.Expr) Dao {
+	value := reflect.Indirect(reflect.ValueOf(filters))
+	if value.Kind() != reflect.Struct {
+		return d.withError(fmt.Errorf("filters must be a struct, got %T", filters))
+	}
+	for name := range mapping {
+		if _, ok := value.Type().FieldByName(name); !ok {
+			return d.withError(fmt.Errorf("unknown filter field %q in mapping", name))
+		}
+	}
+
+	var exprs []clause.Expression
+	for i := 0; i < value.NumField(); i++ {
+		column, ok := mapping[value.Type().Field(i).Name]
+		if !ok {
+			continue
+		}
+		fieldValue := value.Field(i)
+		switch fieldValue.Kind() {
+		case reflect.Ptr, reflect.Interface, reflect.Map, reflect.Slice:
+			if fieldValue.IsNil() {
+				continue
+			}
+		}
+		exprs = append(exprs, clause.Eq{Column: column.RawExpr(), Value: reflect.Indirect(fieldValue).Interface()})
+	}
+	if len(exprs) == 0 {
+		return d
+	}
+	return d.getInstance(d.db.Clauses(clause.Where{Exprs: exprs}))
+}
+
+// ExcludeSoftDeleted filter out soft deleted rows, equal to WHERE deletedAt IS NULL,
+// unlike the soft delete of gorm it works on any column and can be undone by IncludeSoftDeleted
+func (d *DO) ExcludeSoftDeleted(deletedAt field.Expr) Dao {
+	cond := softDeletedCond{clause.Expr{SQL: "? IS NULL", Vars: []interface{}{deletedAt.RawExpr()}}}
+	return d.getInstance(d.db.Clauses(clause.Where{Exprs: []clause.Expression{cond}}))
+}
+
+// IncludeSoftDeleted drop the conditions added by ExcludeSoftDeleted, other conditions are kept
+func (d *DO) IncludeSoftDeleted() Dao {
+	c, ok := d.db.Statement.Clauses[clause.Where{}.Name()]
+	if !ok {
+		return d
+	}
+	where, ok := c.Expression.(clause.Where)
+	if !ok {
+		return d
+	}
+
+	exprs := make([]clause.Expression, 0, len(where.Exprs))
+	for _, e := range where.Exprs {
+		if _, ok := e.(softDeletedCond); !ok {
+			exprs = append(exprs, e)
+		}
+	}
+
+	tx := d.db.Session(&gorm.Session{}).Clauses()
+	if len(exprs) == 0 {
+		delete(tx.Statement.Clauses, c.Name)
+	} else {
+		c.Expression = clause.Where{Exprs: exprs}
+		tx.Statement.Clauses[c.Name] = c
+	}
+	return d.getInstance(tx)
+}
+
+// softDeletedCond condition added by ExcludeSoftDeleted
+type softDeletedCond struct{ clause.Expression }
+
+// AsOf filter rows valid at ts of a temporal table, equal to WHERE validFrom <= ts AND validTo > ts
+func (d *DO) AsOf(ts time.Time, validFrom, validTo field.Expr) Dao {
+	return d.getInstance(d.db.Clauses(clause.Where{Exprs: []clause.Expression{
+		clause.Lte{Column: validFrom.RawExpr(), Value: ts},
+		clause.Gt{Column: validTo.RawExpr(), Value: ts},
+	}}))
+}
+
 // Order ...
 func (d *DO) Order(columns ...field.Expr) Dao {
 	// lazy build Columns
@@ -258,6 +358,85 @@ func (d *DO) Order(columns ...field.Expr) Dao {
 	return d.getInstance(d.db.Order(d.toOrderValue(columns...)))
 }
 
+// Lock lock selected rows, equal to FOR strength, strength is one of
+// UPDATE, NO KEY UPDATE, SHARE and KEY SHARE
+func (d *DO) Lock(strength string) Dao {
+	strength = strings.ToUpper(strings.TrimSpace(strength))
+	if !lockingStrengths[strength] {
+		return d.withError(fmt.Errorf("unsupported locking strength: %s", strength))
+	}
+	return d.getInstance(d.db.Clauses(clause.Locking{Strength: strength}))
+}
+
+var lockingStrengths = map[string]bool{
+	clause.LockingStrengthUpdate: true,
+	"NO KEY UPDATE":              true,
+	clause.LockingStrengthShare:  true,
+	"KEY SHARE":                  true,
+}
+
+// LockParent lock selected parent rows while still allowing inserts of rows referencing them,
+// equal to FOR NO KEY UPDATE
+func (d *DO) LockParent() Dao {
+	return d.Lock("NO KEY UPDATE")
+}
+
+// OrderByAlias order by an output column name of SELECT, e.g. the alias of an aggregate,
+// instead of rebuilding the expression
+func (d *DO) OrderByAlias(name string, desc bool) Dao {
+	return d.getInstance(d.db.Order(clause.OrderByColumn{Column: clause.Column{Name: name}, Desc: desc}))
+}
+
+// OrderByCustom order by the position of col in order, e.g. statuses in their workflow order,
+// equal to array_position(ARRAY[order...], col) on postgres and FIELD(col, order...) on mysql
+func (d *DO) OrderByCustom(col field.Expr, order []interface{}) Dao {
+	if len(order) == 0 {
+		return d
+	}
+
+	items := strings.TrimSuffix(strings.Repeat("?,", len(order)), ",")
+	var e clause.Expr
+	switch name := d.db.Dialector.Name(); name {
+	case "postgres":
+		e = clause.Expr{SQL: "array_position(ARRAY[" + items + "], ?)", Vars: append(append([]interface{}{}, order...), col.RawExpr())}
+	case "mysql":
+		e = clause.Expr{SQL: "FIELD(?," + items + ")", Vars: append([]interface{}{col.RawExpr()}, order...)}
+	default:
+		return d.withError(fmt.Errorf("custom order %w %s", ErrUnsupportedDialect, name))
+	}
+	return d.Order(field.NewExpr("", e))
+}
+
+// StableOrder append primaryKey to ORDER BY as the final tiebreaker unless it is already ordered by,
+// so paginated queries return rows in a deterministic order
+func (d *DO) StableOrder(primaryKey field.Expr) Dao {
+	pk := d.toOrderValue(primaryKey)
+	if c, ok := d.db.Statement.Clauses[clause.OrderBy{}.Name()]; ok {
+		if orderBy, ok := c.Expression.(clause.OrderBy); ok {
+			for _, column := range orderBy.Columns {
+				if d.orderedBy(column, pk) {
+					return d
+				}
+			}
+		}
+	}
+	return d.Order(primaryKey)
+}
+
+// orderedBy report whether order column sorts by col, raw columns may hold several comma separated items
+func (d *DO) orderedBy(column clause.OrderByColumn, col string) bool {
+	if !column.Column.Raw {
+		return d.db.Statement.Quote(column.Column) == col
+	}
+	for _, item := range strings.Split(column.Column.Name, ",") {
+		fields := strings.Fields(item)
+		if len(fields) > 0 && fields[0] == col {
+			return true
+		}
+	}
+	return false
+}
+
 func (d *DO) toOrderValue(columns ...field.Expr) string {
 	// eager build Columns
 	stmt := &gorm.Statement{DB: d.db.Statement.DB, Table: d.db.Statement.Table, Schema: d.db.Statement.Schema}
@@ -274,7 +453,7 @@ func (d *DO) toOrderValue(columns ...field.Expr) string {
 
 // Distinct ...
 func (d *DO) Distinct(columns ...field.Expr) Dao {
-	return d.getInstance(d.db.Distinct(toInterfaceSlice(toColExprFullName(d.db.Statement, columns...))...))
+	return d.withSelects(d.db.Distinct(toInterfaceSlice(toColExprFullName(d.db.Statement, columns...))...), columns)
 }
 
 // Omit ...
@@ -286,26 +465,102 @@ func (d *DO) Omit(columns ...field.Expr) Dao {
 }
 
 // Group ...
+// aliased columns and expressions are grouped by the expression itself, the alias is dropped,
+// use GroupByAlias to group by an output column name of SELECT. Vars of expressions are bound
+// like in SELECT, so the same expression renders the same SQL in both clauses. Rows whose key
+// is NULL fall into one group, GROUP BY compares keys as IS NOT DISTINCT FROM.
 func (d *DO) Group(columns ...field.Expr) Dao {
-	if len(columns) == 0 {
+	keys := make([]interface{}, 0, len(columns))
+	for _, c := range columns {
+		if c != nil {
+			keys = append(keys, unaliased(c))
+		}
+	}
+	if len(keys) == 0 {
 		return d
 	}
+	return d.getInstance(d.db.Clauses(groupByClause{keys: keys}))
+}
 
-	stmt := &gorm.Statement{DB: d.db.Statement.DB, Table: d.db.Statement.Table, Schema: d.db.Statement.Schema}
+// GroupByAlias group by output column names of SELECT, e.g. the alias of a computed expression,
+// instead of rebuilding the expression, an alias equal to a column name of the table is rejected
+// with ErrAliasShadowsColumn, since postgres and mysql resolve such a name to the input column
+func (d *DO) GroupByAlias(names ...string) Dao {
+	if len(names) == 0 {
+		return d
+	}
 
-	for i, c := range columns {
-		if i != 0 {
-			stmt.WriteByte(',')
+	keys := make([]interface{}, 0, len(names))
+	for _, name := range names {
+		if sch := d.db.Statement.Schema; sch != nil && sch.LookUpField(name) != nil {
+			return d.withError(fmt.Errorf("group by %w: %s", ErrAliasShadowsColumn, name))
 		}
-		c.Build(stmt)
+		keys = append(keys, clause.Column{Name: name})
+	}
+	return d.getInstance(d.db.Clauses(groupByClause{keys: keys}))
+}
+
+// groupByClause keys of GROUP BY added by Group and GroupByAlias, built as vars of the statement,
+// it shares the GROUP BY clause with HAVING and columns of clause.GroupBy
+type groupByClause struct {
+	keys []interface{}
+}
+
+func (groupByClause) Name() string { return "GROUP BY" }
+
+func (c groupByClause) Build(builder clause.Builder) {
+	for i, key := range c.keys {
+		if i > 0 {
+			builder.WriteByte(',')
+		}
+		builder.AddVar(builder, key)
+	}
+}
+
+func (c groupByClause) MergeClause(cl *clause.Clause) {
+	if g, ok := cl.AfterNameExpression.(groupByClause); ok {
+		c.keys = append(append([]interface{}{}, g.keys...), c.keys...)
+	}
+	cl.AfterNameExpression = c
+	cl.Builder = buildGroupBy
+	if cl.Expression == nil {
+		cl.Expression = clause.GroupBy{}
 	}
+}
 
-	return d.getInstance(d.db.Group(stmt.SQL.String()))
+// buildGroupBy write keys of groupByClause before columns and HAVING of clause.GroupBy
+func buildGroupBy(c clause.Clause, builder clause.Builder) {
+	builder.WriteString("GROUP BY ")
+	c.AfterNameExpression.Build(builder)
+	if groupBy, ok := c.Expression.(clause.GroupBy); ok {
+		if len(groupBy.Columns) > 0 {
+			builder.WriteByte(',')
+		}
+		groupBy.Build(builder)
+	}
+}
+
+// unaliased return raw expression of col without the alias set by As
+func unaliased(col field.Expr) interface{} {
+	switch raw := col.RawExpr().(type) {
+	case clause.Column:
+		raw.Alias = ""
+		return raw
+	case clause.Expr:
+		if raw.SQL == "? AS ?" && len(raw.Vars) == 2 {
+			if e, ok := raw.Vars[0].(clause.Expression); ok {
+				return e
+			}
+		}
+		return raw
+	default:
+		return col
+	}
 }
 
 // Having ...
 func (d *DO) Having(conds ...Condition) Dao {
-	exprs, err := condToExpression(conds)
+	exprs, err := d.condToExpression(conds)
 	if err != nil {
 		return d.withError(err)
 	}
@@ -315,6 +570,56 @@ func (d *DO) Having(conds ...Condition) Dao {
 	return d.getInstance(d.db.Clauses(clause.GroupBy{Having: exprs}))
 }
 
+// DefineWindow define a window named name in WINDOW clause, functions refer to it by OverNamed,
+// e.g. SELECT SUM(score) OVER w FROM t WINDOW w AS (PARTITION BY dept)
+func (d *DO) DefineWindow(name string, spec field.WindowSpec) Dao {
+	tx := d.db.Clauses(windowClause{definitions: []field.Expr{field.NamedWindow(name, spec)}})
+	tx.Statement.BuildClauses = withWindowClause(tx.Statement.BuildClauses, d.db.Callback().Query().Clauses)
+	return d.getInstance(tx)
+}
+
+// windowClause WINDOW clause, definitions of multiple calls are joined
+type windowClause struct {
+	definitions []field.Expr
+}
+
+func (windowClause) Name() string { return "WINDOW" }
+
+func (c windowClause) Build(builder clause.Builder) {
+	for i, definition := range c.definitions {
+		if i > 0 {
+			builder.WriteByte(',')
+		}
+		builder.AddVar(builder, definition.RawExpr())
+	}
+}
+
+func (c windowClause) MergeClause(cl *clause.Clause) {
+	if w, ok := cl.Expression.(windowClause); ok {
+		c.definitions = append(append([]field.Expr{}, w.definitions...), c.definitions...)
+	}
+	cl.Expression = c
+}
+
+// withWindowClause insert WINDOW after GROUP BY (and its HAVING) into clauses to build,
+// clauses of the query callback are used if none is set
+func withWindowClause(clauses, queryClauses []string) []string {
+	if len(clauses) == 0 {
+		clauses = queryClauses
+	}
+	result := make([]string, 0, len(clauses)+1)
+	for _, name := range clauses {
+		if name == "WINDOW" {
+			return clauses
+		}
+		result = append(result, name)
+		if name == "GROUP BY" {
+			result = append(result, "WINDOW")
+		}
+	}
+	return result
+}
+
 // Limit ...
 func (d *DO) Limit(limit int) Dao {
 	return d.getInstance(d.db.Limit(limit))
@@ -325,6 +630,144 @@ func (d *DO) Offset(offset int) Dao {
 	return d.getInstance(d.db.Offset(offset))
 }
 
+// AutoKeyset order the query by orderBy and turn its OFFSET into a keyset condition if the order is deterministic,
+// i.e. orderBy are plain columns sorted in the same direction and include pk, e.g. Limit(10).Offset(20) becomes
+//
+//	WHERE (a, id) > (SELECT a, id FROM t WHERE ... ORDER BY a, id LIMIT 1 OFFSET 19) ORDER BY a, id LIMIT 10
+//
+// which lets the database seek the page by an index on the order columns. otherwise OFFSET is kept.
+// NULL values of order columns are not matched by the keyset condition, so the columns should be NOT NULL
+func (d *DO) AutoKeyset(orderBy []field.OrderExpr, pk field.Expr) Dao {
+	orders := make([]field.Expr, len(orderBy))
+	for i, o := range orderBy {
+		orders[i] = o
+	}
+
+	var offset int
+	if c, ok := d.db.Statement.Clauses[clause.Limit{}.Name()]; ok {
+		if limit, ok := c.Expression.(clause.Limit); ok {
+			offset = limit.Offset
+		}
+	}
+	columns, desc, ok := keysetColumns(orders, pk)
+	if !ok || offset <= 0 {
+		return d.Order(orders...)
+	}
+
+	keys := make([]field.Expr, len(columns))
+	vars := make([]interface{}, 0, len(columns)+1)
+	for i, col := range columns {
+		keys[i] = field.NewField(col.Table, col.Name)
+		vars = append(vars, col)
+	}
+	sub := d.getInstance(d.db.Session(&gorm.Session{})).Select(keys...).Order(orders...).Offset(offset - 1).Limit(1).(*DO)
+	op := ">"
+	if desc {
+		op = "<"
+	}
+	cond := clause.Expr{
+		SQL:  "(" + strings.TrimSuffix(strings.Repeat("?,", len(columns)), ",") + ") " + op + " (?)",
+		Vars: append(vars, sub.db.Table(sub.TableName())),
+	}
+	return d.getInstance(d.db.Clauses(clause.Where{Exprs: []clause.Expression{cond}})).Order(orders...).Offset(-1)
+}
+
+// keysetColumns return columns of orders and their direction, ok is false unless all orders are columns
+// sorted in the same direction and pk is one of them
+func keysetColumns(orders []field.Expr, pk field.Expr) (columns []clause.Column, desc bool, ok bool) {
+	var hasPK bool
+	for i, o := range orders {
+		raw, isDesc := o.RawExpr(), false
+		if e, sorted := raw.(clause.Expr); sorted && len(e.Vars) == 1 && (e.SQL == "? DESC" || e.SQL == "? ASC") {
+			raw, isDesc = e.Vars[0], e.SQL == "? DESC"
+		}
+		col, isColumn := raw.(clause.Column)
+		if !isColumn || (i > 0 && isDesc != desc) {
+			return nil, false, false
+		}
+		desc = isDesc
+		hasPK = hasPK || col.Name == pk.ColumnName().String()
+		columns = append(columns, col)
+	}
+	return columns, desc, hasPK
+}
+
+// LimitSafe is like Limit, but a negative limit is rejected with ErrNegativeLimit
+// instead of cancelling the limit, and zero limit returns no rows
+func (d *DO) LimitSafe(limit int) Dao {
+	if limit < 0 {
+		return d.withError(fmt.Errorf("%w: limit %d", ErrNegativeLimit, limit))
+	}
+	return d.getInstance(d.db.Limit(limit))
+}
+
+// OffsetSafe is like Offset, but a negative offset is rejected with ErrNegativeLimit
+func (d *DO) OffsetSafe(offset int) Dao {
+	if offset < 0 {
+		return d.withError(fmt.Errorf("%w: offset %d", ErrNegativeLimit, offset))
+	}
+	return d.getInstance(d.db.Offset(offset))
+}
+
+// FetchFirst equal to FETCH FIRST n ROWS ONLY, or FETCH FIRST n ROWS WITH TIES when withTies is true,
+// it takes the place of LIMIT, so Offset must be called before it
+func (d *DO) FetchFirst(n int, withTies bool) Dao {
+	if n < 0 {
+		return d.withError(fmt.Errorf("%w: fetch first %d", ErrNegativeLimit, n))
+	}
+	return d.getInstance(d.db.Clauses(fetchFirst{Count: n, WithTies: withTies}))
+}
+
+// FetchFirstPercent equal to FETCH FIRST pct PERCENT ROWS ONLY or WITH TIES,
+// only dialects in fetchPercentDialects support it, others get ErrUnsupportedDialect
+func (d *DO) FetchFirstPercent(pct float64, withTies bool) Dao {
+	if name := d.db.Dialector.Name(); !fetchPercentDialects[name] {
+		return d.withError(fmt.Errorf("FETCH FIRST PERCENT %w %s", ErrUnsupportedDialect, name))
+	}
+	if pct < 0 {
+		return d.withError(fmt.Errorf("%w: fetch first %v percent", ErrNegativeLimit, pct))
+	}
+	return d.getInstance(d.db.Clauses(fetchFirst{Count: pct, Percent: true, WithTies: withTies}))
+}
+
+var fetchPercentDialects = map[string]bool{"oracle": true}
+
+// fetchFirst FETCH FIRST clause, it takes the place of LIMIT clause and keeps its offset
+type fetchFirst struct {
+	Count    interface{}
+	Percent  bool
+	WithTies bool
+	Offset   int
+}
+
+func (fetchFirst) Name() string { return "LIMIT" }
+
+func (f fetchFirst) Build(builder clause.Builder) {
+	if f.Offset > 0 {
+		builder.WriteString("OFFSET ")
+		builder.AddVar(builder, f.Offset)
+		builder.WriteString(" ROWS ")
+	}
+	builder.WriteString("FETCH FIRST ")
+	builder.AddVar(builder, f.Count)
+	if f.Percent {
+		builder.WriteString(" PERCENT")
+	}
+	if f.WithTies {
+		builder.WriteString(" ROWS WITH TIES")
+	} else {
+		builder.WriteString(" ROWS ONLY")
+	}
+}
+
+func (f fetchFirst) MergeClause(c *clause.Clause) {
+	c.Name = ""
+	if v, ok := c.Expression.(clause.Limit); ok {
+		f.Offset = v.Offset
+	}
+	c.Expression = f
+}
+
 // Scopes ...
 func (d *DO) Scopes(funcs ...func(Dao) Dao) Dao {
 	fcs := make([]func(*gorm.DB) *gorm.DB, len(funcs))
@@ -355,20 +798,128 @@ func (d *DO) RightJoin(table schema.Tabler, conds ...field.Expr) Dao {
 	return d.join(table, clause.RightJoin, conds)
 }
 
+// CrossJoin equal to CROSS JOIN table, an intended cartesian product
+func (d *DO) CrossJoin(table string) Dao {
+	from := getFromClause(d.db)
+	from.Joins = append(from.Joins, clause.Join{Type: clause.CrossJoin, Table: clause.Table{Name: table}})
+	return d.getInstance(d.db.Clauses(from))
+}
+
+// CrossJoinLateral equal to CROSS JOIN LATERAL (sub) AS alias, sub can reference columns of preceding tables
+func (d *DO) CrossJoinLateral(sub SubQuery, alias string) Dao {
+	subDO := sub.underlyingDO()
+	from := getFromClause(d.db)
+	from.Joins = append(from.Joins, clause.Join{
+		Type:  clause.CrossJoin,
+		Table: clause.Table{Name: alias},
+		Expression: clause.Expr{
+			SQL:  "CROSS JOIN LATERAL (?) AS ?",
+			Vars: []interface{}{subDO.db.Table(subDO.TableName()), clause.Table{Name: alias}},
+		},
+	})
+	return d.getInstance(d.db.Clauses(from))
+}
+
+// CrossJoinLateralFunc equal to CROSS JOIN LATERAL fn AS alias(columns), fn is a set returning function such as Unnest
+func (d *DO) CrossJoinLateralFunc(fn field.Expr, alias string, columns ...string) Dao {
+	var vars = []interface{}{fn.RawExpr(), clause.Table{Name: alias}}
+	var sql = "CROSS JOIN LATERAL ? AS ?"
+	if len(columns) > 0 {
+		cols := make([]interface{}, len(columns))
+		for i, c := range columns {
+			cols[i] = clause.Column{Name: c}
+		}
+		sql += "(" + strings.TrimSuffix(strings.Repeat("?,", len(cols)), ",") + ")"
+		vars = append(vars, cols...)
+	}
+	from := getFromClause(d.db)
+	from.Joins = append(from.Joins, clause.Join{
+		Type:       clause.CrossJoin,
+		Table:      clause.Table{Name: alias},
+		Expression: clause.Expr{SQL: sql, Vars: vars},
+	})
+	return d.getInstance(d.db.Clauses(from))
+}
+
 func (d *DO) join(table schema.Tabler, joinType clause.JoinType, conds []field.Expr) Dao {
 	if len(conds) == 0 {
 		return d.withError(ErrEmptyCondition)
 	}
+	joins := toClauseJoins(field.RelationJoin{Table: table, Type: joinType, Condition: conds})
+	if d.strictJoin() {
+		if err := checkCartesianJoins(joins); err != nil {
+			return d.withError(err)
+		}
+	}
 	from := getFromClause(d.db)
-	from.Joins = append(from.Joins, toClauseJoins(field.RelationJoin{Table: table, Type: joinType, Condition: conds})...)
+	from.Joins = append(from.Joins, joins...)
 	return d.getInstance(d.db.Clauses(from))
 }
 
+func (d *DO) strictJoin() bool { return d.DOConfig != nil && d.DOConfig.StrictJoin }
+
+func (d *DO) strictWindowColumns() bool { return d.DOConfig != nil && d.DOConfig.StrictWindowColumns }
+
+func (d *DO) strictTyping() bool { return d.DOConfig != nil && d.DOConfig.StrictTyping }
+
+// condToExpression convert conds to expressions, comparisons of mismatched types are reported in strict typing mode
+func (d *DO) condToExpression(conds []Condition) ([]clause.Expression, error) {
+	if d.strictTyping() {
+		for _, cond := range conds {
+			if e, ok := cond.(field.Expr); ok {
+				if err := field.CheckTyping(e); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	return condToExpression(conds)
+}
+
+// checkCartesianJoins return ErrCartesianJoin if a join other than CROSS JOIN has neither ON nor USING conditions
+func checkCartesianJoins(joins []clause.Join) error {
+	for _, join := range joins {
+		if join.Type == clause.CrossJoin || len(join.Using) > 0 {
+			continue
+		}
+		if !hasCondition(join.ON.Exprs) {
+			return fmt.Errorf("%w: %s", ErrCartesianJoin, join.Table.Name)
+		}
+	}
+	return nil
+}
+
+func hasCondition(exprs []clause.Expression) bool {
+	for _, e := range exprs {
+		switch v := e.(type) {
+		case nil:
+		case clause.Expr:
+			if strings.TrimSpace(v.SQL) != "" {
+				return true
+			}
+		case clause.AndConditions:
+			if hasCondition(v.Exprs) {
+				return true
+			}
+		case clause.OrConditions:
+			if hasCondition(v.Exprs) {
+				return true
+			}
+		default:
+			return true
+		}
+	}
+	return false
+}
+
 // Attrs ...
 func (d *DO) Attrs(attrs ...field.AssignExpr) Dao {
 	if len(attrs) == 0 {
 		return d
 	}
+	if err := assignError(attrs); err != nil {
+		return d.withError(err)
+	}
 	return d.getInstance(d.db.Attrs(d.attrsValue(attrs)...))
 }
 
@@ -377,6 +928,9 @@ func (d *DO) Assign(attrs ...field.AssignExpr) Dao {
 	if len(attrs) == 0 {
 		return d
 	}
+	if err := assignError(attrs); err != nil {
+		return d.withError(err)
+	}
 	return d.getInstance(d.db.Assign(d.attrsValue(attrs)...))
 }
 
@@ -568,6 +1122,36 @@ func (d *DO) UpdateFrom(q SubQuery) Dao {
 	return d.getInstance(d.db.Clauses(clause.Update{Table: clause.Table{Name: tableName.String(), Raw: true}}))
 }
 
+// sourceClause returns source as a FROM/USING item: (subquery) AS alias or table AS alias
+func (d *DO) sourceClause(keyword string, source SubQuery) clause.Expr {
+	sourceDO := source.underlyingDO()
+	name := sourceDO.alias
+	if name == "" {
+		name = sourceDO.TableName()
+	}
+
+	db := source.underlyingDB()
+	if _, ok := db.Statement.Clauses["SELECT"]; ok || len(db.Statement.Selects) > 0 {
+		return clause.Expr{SQL: keyword + " (?) AS ?", Vars: []interface{}{db.Table(sourceDO.TableName()), clause.Table{Name: name}}}
+	}
+	if name != sourceDO.TableName() {
+		return clause.Expr{SQL: keyword + " ? AS ?", Vars: []interface{}{clause.Table{Name: sourceDO.TableName()}, clause.Table{Name: name}}}
+	}
+	return clause.Expr{SQL: keyword + " ?", Vars: []interface{}{clause.Table{Name: name}}}
+}
+
+// afterClause appends expression to the clause named name
+type afterClause struct {
+	name string
+	expr clause.Expression
+}
+
+func (c afterClause) Name() string { return c.name }
+
+func (c afterClause) Build(builder clause.Builder) { c.expr.Build(builder) }
+
+func (c afterClause) MergeClause(cl *clause.Clause) { cl.AfterExpression = c.expr }
+
 func getFromClause(db *gorm.DB) *clause.From {
 	if db == nil || db.Statement == nil {
 		return &clause.From{}
@@ -587,17 +1171,129 @@ func getFromClause(db *gorm.DB) *clause.From {
 
 // Create ...
 func (d *DO) Create(value interface{}) error {
-	return d.db.Create(value).Error
+	batchSize, err := d.bindVarsBatchSize(value, 0)
+	if err != nil {
+		return err
+	}
+	if batchSize > 0 {
+		return translateError(d.db.CreateInBatches(value, batchSize).Error)
+	}
+	return translateError(d.db.Create(value).Error)
+}
+
+// InsertColumns insert rows of values into columns in the order of columns, so the generated SQL is reproducible,
+// every row must have a value per column
+func (d *DO) InsertColumns(columns []field.Expr, values [][]interface{}) error {
+	if len(columns) == 0 {
+		return fmt.Errorf("no columns to insert into %s", d.TableName())
+	}
+	if len(values) == 0 {
+		return nil
+	}
+
+	names := make([]interface{}, len(columns))
+	for i, col := range columns {
+		names[i] = clause.Column{Name: col.ColumnName().String()}
+	}
+	rows := make([]string, len(values))
+	vars := append(make([]interface{}, 0, len(values)+2), clause.Table{Name: d.TableName()}, names)
+	for i, row := range values {
+		if len(row) != len(columns) {
+			return fmt.Errorf("row %d has %d values, expect %d", i, len(row), len(columns))
+		}
+		rows[i] = "?"
+		vars = append(vars, row)
+	}
+	return translateError(d.db.Exec("INSERT INTO ? ? VALUES "+strings.Join(rows, ","), vars...).Error)
 }
 
 // CreateInBatches ...
 func (d *DO) CreateInBatches(value interface{}, batchSize int) error {
-	return d.db.CreateInBatches(value, batchSize).Error
+	batchSize, err := d.bindVarsBatchSize(value, batchSize)
+	if err != nil {
+		return err
+	}
+	return translateError(d.db.CreateInBatches(value, batchSize).Error)
 }
 
 // Save ...
 func (d *DO) Save(value interface{}) error {
-	return d.db.Clauses(clause.OnConflict{UpdateAll: true}).Create(value).Error
+	batchSize, err := d.bindVarsBatchSize(value, 0)
+	if err != nil {
+		return err
+	}
+	if batchSize > 0 {
+		return translateError(d.db.Clauses(clause.OnConflict{UpdateAll: true}).CreateInBatches(value, batchSize).Error)
+	}
+	return translateError(d.db.Clauses(clause.OnConflict{UpdateAll: true}).Create(value).Error)
+}
+
+// UpsertReturningInserted insert a row or update it on conflict, inserted reports whether the row is newly inserted,
+// which is told by (xmax = 0) of the returned row on postgres. value is created as Create does, hooks are called
+// and columns of the returned row are filled into value, it must be a single row
+func (d *DO) UpsertReturningInserted(value interface{}) (inserted bool, err error) {
+	if name := d.db.Dialector.Name(); name != "postgres" {
+		return false, fmt.Errorf("upsert returning inserted %w %s", ErrUnsupportedDialect, name)
+	}
+
+	// the flag is not a column of the model, so it's kept in a transaction local setting instead of being scanned into value
+	returning := clause.Returning{Columns: []clause.Column{
+		{Name: "*", Raw: true},
+		{Name: "set_config('" + upsertInsertedSetting + "', (xmax = 0)::text, true)", Raw: true},
+	}}
+	err = d.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.OnConflict{UpdateAll: true}, returning).Create(value).Error; err != nil {
+			return err
+		}
+		return tx.Session(&gorm.Session{NewDB: true}).Raw("SELECT current_setting('" + upsertInsertedSetting + "')::boolean").Scan(&inserted).Error
+	})
+	return inserted, translateError(err)
+}
+
+const upsertInsertedSetting = "gen.upsert_inserted"
+
+// bindVarsBatchSize return rows count of each insert statement to keep its bind vars under the limit,
+// batchSize is kept when it's small enough, 0 means value can be inserted by a single statement
+func (d *DO) bindVarsBatchSize(value interface{}, batchSize int) (int, error) {
+	rows := reflect.Indirect(reflect.ValueOf(value))
+	if rows.Kind() != reflect.Slice && rows.Kind() != reflect.Array {
+		return batchSize, nil
+	}
+
+	stmt := &gorm.Statement{DB: d.db}
+	if err := stmt.Parse(value); err != nil { // leave unsupported value to gorm
+		return batchSize, nil
+	}
+	var columns int
+	for _, f := range stmt.Schema.Fields {
+		if f.DBName != "" && f.Creatable {
+			columns++
+		}
+	}
+	if columns == 0 {
+		return batchSize, nil
+	}
+
+	limit := d.maxBindVars()
+	if columns > limit {
+		return 0, fmt.Errorf("%w: %d columns per row, limit %d", ErrTooManyBindVars, columns, limit)
+	}
+	maxRows := limit / columns
+	switch {
+	case batchSize > 0 && batchSize <= maxRows:
+		return batchSize, nil
+	case batchSize <= 0 && rows.Len() <= maxRows:
+		return 0, nil
+	default:
+		return maxRows, nil
+	}
+}
+
+func (d *DO) maxBindVars() int {
+	if d.DOConfig != nil && d.DOConfig.MaxBindVars > 0 {
+		return d.DOConfig.MaxBindVars
+	}
+	return DefaultMaxBindVars
 }
 
 // First ...
@@ -677,13 +1373,36 @@ func (d *DO) Update(column field.Expr, value interface{}) (info ResultInfo, err
 	var result *gorm.DB
 	switch value := value.(type) {
 	case field.AssignExpr:
+		if err = value.CondError(); err != nil {
+			return ResultInfo{Error: err}, err
+		}
 		result = tx.Update(columnStr, value.AssignExpr())
 	case SubQuery:
 		result = tx.Update(columnStr, value.underlyingDB())
 	default:
 		result = tx.Update(columnStr, value)
 	}
-	return ResultInfo{RowsAffected: result.RowsAffected, Error: result.Error}, result.Error
+	err = translateError(result.Error)
+	return ResultInfo{RowsAffected: result.RowsAffected, Error: err}, err
+}
+
+// OnlyIfChanged add guards of field.SetIfChanged assignments to WHERE joined by OR,
+// rows whose columns already hold the assigned values are not updated
+func (d *DO) OnlyIfChanged(assignments ...field.AssignExpr) Dao {
+	if err := assignError(assignments); err != nil {
+		return d.withError(err)
+	}
+
+	var guards []field.Expr
+	for _, assignment := range assignments {
+		if guard, ok := assignment.(field.ChangeGuard); ok {
+			guards = append(guards, guard.Guard())
+		}
+	}
+	if len(guards) == 0 {
+		return d
+	}
+	return d.Where(field.Or(guards...))
 }
 
 // UpdateSimple ...
@@ -691,13 +1410,24 @@ func (d *DO) UpdateSimple(columns ...field.AssignExpr) (info ResultInfo, err err
 	if len(columns) == 0 {
 		return
 	}
+	if err = assignError(columns); err != nil {
+		return ResultInfo{Error: err}, err
+	}
 
 	result := d.db.Clauses(d.assignSet(columns)).Omit("*").Updates(map[string]interface{}{})
-	return ResultInfo{RowsAffected: result.RowsAffected, Error: result.Error}, result.Error
+	err = translateError(result.Error)
+	return ResultInfo{RowsAffected: result.RowsAffected, Error: err}, err
 }
 
 // Updates ...
 func (d *DO) Updates(value interface{}) (info ResultInfo, err error) {
+	if list, ok := value.(AssignmentList); ok {
+		if err = list.validate(); err != nil {
+			return ResultInfo{Error: err}, err
+		}
+		return d.UpdateSimple(list...)
+	}
+
 	var rawTyp, valTyp reflect.Type
 
 	rawTyp = reflect.TypeOf(value)
@@ -718,7 +1448,8 @@ func (d *DO) Updates(value interface{}) (info ResultInfo, err error) {
 	}
 
 	result := tx.Updates(value)
-	return ResultInfo{RowsAffected: result.RowsAffected, Error: result.Error}, result.Error
+	err = translateError(result.Error)
+	return ResultInfo{RowsAffected: result.RowsAffected, Error: err}, err
 }
 
 // UpdateColumn ...
@@ -729,13 +1460,17 @@ func (d *DO) UpdateColumn(column field.Expr, value interface{}) (info ResultInfo
 	var result *gorm.DB
 	switch value := value.(type) {
 	case field.Expr:
+		if err = value.CondError(); err != nil {
+			return ResultInfo{Error: err}, err
+		}
 		result = tx.UpdateColumn(columnStr, value.RawExpr())
 	case SubQuery:
 		result = d.db.UpdateColumn(columnStr, value.underlyingDB())
 	default:
 		result = d.db.UpdateColumn(columnStr, value)
 	}
-	return ResultInfo{RowsAffected: result.RowsAffected, Error: result.Error}, result.Error
+	err = translateError(result.Error)
+	return ResultInfo{RowsAffected: result.RowsAffected, Error: err}, err
 }
 
 // UpdateColumnSimple ...
@@ -743,15 +1478,52 @@ func (d *DO) UpdateColumnSimple(columns ...field.AssignExpr) (info ResultInfo, e
 	if len(columns) == 0 {
 		return
 	}
+	if err = assignError(columns); err != nil {
+		return ResultInfo{Error: err}, err
+	}
 
 	result := d.db.Clauses(d.assignSet(columns)).Omit("*").UpdateColumns(map[string]interface{}{})
-	return ResultInfo{RowsAffected: result.RowsAffected, Error: result.Error}, result.Error
+	err = translateError(result.Error)
+	return ResultInfo{RowsAffected: result.RowsAffected, Error: err}, err
 }
 
 // UpdateColumns ...
 func (d *DO) UpdateColumns(value interface{}) (info ResultInfo, err error) {
+	if list, ok := value.(AssignmentList); ok {
+		if err = list.validate(); err != nil {
+			return ResultInfo{Error: err}, err
+		}
+		return d.UpdateColumnSimple(list...)
+	}
+
 	result := d.db.UpdateColumns(value)
-	return ResultInfo{RowsAffected: result.RowsAffected, Error: result.Error}, result.Error
+	err = translateError(result.Error)
+	return ResultInfo{RowsAffected: result.RowsAffected, Error: err}, err
+}
+
+// UpdateFromSubQuery update rows joined with source, equal to
+// UPDATE table SET assignments FROM (source) AS alias WHERE on
+func (d *DO) UpdateFromSubQuery(source SubQuery, on field.Expr, assignments ...field.AssignExpr) error {
+	if len(assignments) == 0 {
+		return nil
+	}
+	if err := assignError(assignments); err != nil {
+		return err
+	}
+
+	do := d.Where(on).(*DO)
+	tx := do.db.Clauses(d.assignSet(assignments), afterClause{name: "SET", expr: d.sourceClause("FROM", source)})
+	return translateError(tx.Omit("*").Updates(map[string]interface{}{}).Error)
+}
+
+// assignError return the first error reported by CondError of assignments, e.g. assigning a generated column
+func assignError(exprs []field.AssignExpr) error {
+	for _, expr := range exprs {
+		if err := expr.CondError(); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // assignSet fetch all set
@@ -786,7 +1558,99 @@ func (d *DO) Delete(models ...interface{}) (info ResultInfo, err error) {
 		}
 		result = d.db.Delete(targets.Interface())
 	}
-	return ResultInfo{RowsAffected: result.RowsAffected, Error: result.Error}, result.Error
+	err = translateError(result.Error)
+	return ResultInfo{RowsAffected: result.RowsAffected, Error: err}, err
+}
+
+// DeleteUsing delete rows joined with source, equal to
+// DELETE FROM table USING (source) AS alias WHERE on
+func (d *DO) DeleteUsing(source SubQuery, on field.Expr) error {
+	do := d.Where(on).(*DO)
+	tx := do.db.Clauses(afterClause{name: "FROM", expr: d.sourceClause("USING", source)})
+	return translateError(tx.Delete(reflect.New(d.modelType).Interface()).Error)
+}
+
+// MaterializeInto save the result of the query into table, e.g. to cache an expensive window report,
+// equal to CREATE TABLE table AS query, or INSERT INTO table query if the table exists
+func (d *DO) MaterializeInto(table string) error {
+	if !tableNameReg.MatchString(table) {
+		return fmt.Errorf("invalid table name %q", table)
+	}
+
+	sql := "CREATE TABLE ? AS ?"
+	if migrator := d.db.Migrator(); migrator != nil && migrator.HasTable(table) {
+		sql = "INSERT INTO ? ?"
+	}
+	return translateError(d.db.Session(&gorm.Session{NewDB: true}).Exec(sql, clause.Table{Name: table}, d.db).Error)
+}
+
+// tableNameReg table name with an optional schema
+var tableNameReg = regexp.MustCompile(`^\w+(\.\w+)?$`)
+
+// CountByConditions select a filtered count per named condition in one scan, columns are aliased by
+// the names in sorted order, e.g. COUNT(*) FILTER (WHERE cond) AS name,
+// only dialects in filterDialects support it, others get ErrUnsupportedDialect
+func (d *DO) CountByConditions(conds map[string]field.Expr) Dao {
+	if name := d.db.Dialector.Name(); !filterDialects[name] {
+		return d.withError(fmt.Errorf("count by conditions %w %s", ErrUnsupportedDialect, name))
+	}
+
+	names := make([]string, 0, len(conds))
+	for name := range conds {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	columns := make([]field.Expr, 0, len(names))
+	for _, name := range names {
+		columns = append(columns, field.NewExpr(name, clause.Expr{
+			SQL:  "COUNT(*) FILTER (WHERE ?) AS ?",
+			Vars: []interface{}{conds[name].RawExpr(), clause.Column{Name: name}},
+		}))
+	}
+	return d.Select(columns...)
+}
+
+// filterDialects dialects supporting FILTER (WHERE ...) of aggregate functions
+var filterDialects = map[string]bool{"postgres": true, "sqlite": true}
+
+// RatePerGroup select groupBy columns and the ratio of rows matching numeratorCond in each group as rate,
+// equal to SELECT groupBy..., COUNT(*) FILTER (WHERE numeratorCond)::float / NULLIF(COUNT(*), 0) AS rate ... GROUP BY groupBy...,
+// it is postgres only, others get ErrUnsupportedDialect
+func (d *DO) RatePerGroup(numeratorCond field.Expr, groupBy []field.Expr) Dao {
+	if name := d.db.Dialector.Name(); name != "postgres" {
+		return d.withError(fmt.Errorf("rate per group %w %s", ErrUnsupportedDialect, name))
+	}
+
+	rate := field.NewExpr("rate", clause.Expr{
+		SQL:  "COUNT(*) FILTER (WHERE ?)::float / NULLIF(COUNT(*), 0) AS ?",
+		Vars: []interface{}{numeratorCond.RawExpr(), clause.Column{Name: "rate"}},
+	})
+	columns := append(append(make([]field.Expr, 0, len(groupBy)+1), groupBy...), rate)
+	return d.Select(columns...).Group(groupBy...)
+}
+
+// WithGrandTotal append a grand total row to the result by UNION ALL,
+// totals are the selected columns of the total row and must align with the columns of the query one by one,
+// a nil total is selected as NULL, e.g. for the grouped columns.
+// The total row keeps the conditions of the query, but not its GROUP BY with HAVING, ORDER BY, LIMIT and OFFSET.
+func (d *DO) WithGrandTotal(totals ...field.Expr) Dao {
+	columns := make([]field.Expr, len(totals))
+	for i, total := range totals {
+		if total == nil {
+			total = field.NewUnsafeFieldRaw("NULL")
+		}
+		columns[i] = total
+	}
+
+	query, args := buildExpr4Select(d.db.Statement, columns...)
+	totalDB := d.db.Session(&gorm.Session{}).Select(query, args...)
+	for _, name := range []string{"GROUP BY", "ORDER BY", "LIMIT"} {
+		delete(totalDB.Statement.Clauses, name)
+	}
+
+	return d.getInstance(d.db.Session(&gorm.Session{NewDB: true}).
+		Table("((?) UNION ALL (?)) AS ?", d.db.Table(d.TableName()), totalDB.Table(d.TableName()), clause.Table{Name: d.TableName()}))
 }
 
 // Count ...
@@ -814,6 +1678,46 @@ func (d *DO) Pluck(column field.Expr, dest interface{}) error {
 	return d.db.Pluck(column.ColumnName().String(), dest).Error
 }
 
+// ScalarFloat select expr from the first row as float64, the error wraps sql.ErrNoRows if no row is found
+func (d *DO) ScalarFloat(expr field.Expr) (float64, error) {
+	var result []sql.NullFloat64
+	if err := d.scalar(expr, &result); err != nil {
+		return 0, err
+	}
+	return result[0].Float64, nil
+}
+
+// ScalarInt select expr from the first row as int64, the error wraps sql.ErrNoRows if no row is found
+func (d *DO) ScalarInt(expr field.Expr) (int64, error) {
+	var result []sql.NullInt64
+	if err := d.scalar(expr, &result); err != nil {
+		return 0, err
+	}
+	return result[0].Int64, nil
+}
+
+// ScalarString select expr from the first row as string, the error wraps sql.ErrNoRows if no row is found
+func (d *DO) ScalarString(expr field.Expr) (string, error) {
+	var result []sql.NullString
+	if err := d.scalar(expr, &result); err != nil {
+		return "", err
+	}
+	return result[0].String, nil
+}
+
+// scalar find expr of at most one row into dest, a pointer to a slice of sql.Null* values.
+// a NULL result such as SUM of no rows is scanned as the zero value
+func (d *DO) scalar(expr field.Expr, dest interface{}) error {
+	tx := d.Select(expr).Limit(1).(*DO).db.Find(dest)
+	if tx.Error != nil {
+		return tx.Error
+	}
+	if tx.RowsAffected == 0 {
+		return fmt.Errorf("scalar %s: %w", expr.ColumnName(), sql.ErrNoRows)
+	}
+	return nil
+}
+
 // ScanRows ...
 func (d *DO) ScanRows(rows *sql.Rows, dest interface{}) error {
 	return d.db.ScanRows(rows, dest)
@@ -987,6 +1891,53 @@ func Table(subQueries ...SubQuery) Dao {
 	}
 }
 
+// NamedSubQuery return sub aliased as alias, so the row of subquery can be referenced by name,
+// e.g. Table(NamedSubQuery(sub, "t")).Select(RowToJSON("t"))
+func NamedSubQuery(sub SubQuery, alias string) SubQuery {
+	if alias == "" {
+		alias = "t"
+	}
+	return sub.underlyingDO().As(alias).(*DO)
+}
+
+// DateSpine return a dense series of dates from from to to by step, e.g. "1 day", as a single column
+// table spine(day), which is the base of LEFT JOIN to fill gaps of aggregates by date
+//
+//	SELECT `day`::date AS `day` FROM generate_series(?, ?, ?::interval) AS `spine`(`day`)
+//
+// it is postgres only, others get ErrUnsupportedDialect
+func (d *DO) DateSpine(from, to time.Time, step string) SubQuery {
+	if name := d.db.Dialector.Name(); name != "postgres" {
+		return d.withError(fmt.Errorf("date spine %w %s", ErrUnsupportedDialect, name))
+	}
+
+	day := clause.Column{Name: "day"}
+	spine := spineFrom{clause.Expr{SQL: "generate_series(?, ?, ?::interval) AS ?(?)", Vars: []interface{}{from, to, step, clause.Table{Name: "spine"}, day}}}
+	return &DO{
+		db: d.db.Session(&gorm.Session{NewDB: true}).Table("spine").Clauses(spine).
+			Select("?::date AS ?", day, day),
+		tableName: "spine",
+	}
+}
+
+// spineFrom FROM clause of a set returning function, it takes the place of the FROM clause of table
+type spineFrom struct{ clause.Expr }
+
+func (spineFrom) Name() string { return "FROM" }
+
+func (f spineFrom) MergeClause(c *clause.Clause) { c.Expression = f }
+
+// RowToJSON return row of table or aliased subquery as json, equal to row_to_json(alias)
+func RowToJSON(alias string) field.Expr {
+	return field.NewExpr("", clause.Expr{SQL: "row_to_json(?)", Vars: []interface{}{clause.Table{Name: alias}}})
+}
+
+// RowInSubQuery row constructor IN subquery expression, used for composite key semi join
+// SELECT * FROM table WHERE (a, b) IN (SELECT a, b FROM other WHERE c = 1)
+func RowInSubQuery(cols []field.Expr, sub SubQuery) field.Expr {
+	return field.ContainsSubQuery(cols, sub.underlyingDB())
+}
+
 // Exists EXISTS expression
 // SELECT * FROM table WHERE EXISTS (SELECT NAME FROM users WHERE id = 1)
 func Exists(subQuery SubQuery) Condition {
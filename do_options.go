@@ -6,7 +6,25 @@ type DOOption interface {
 	AfterInitialize(*DO) error
 }
 
+// DefaultMaxBindVars default bind vars limit of a single statement, which is the limit of Postgres
+const DefaultMaxBindVars = 65535
+
 type DOConfig struct {
+	// MaxBindVars bind vars limit of a single insert statement, batches exceeding it will be split,
+	// DefaultMaxBindVars is used when it's not set
+	MaxBindVars int
+
+	// StrictJoin report a join without ON and USING conditions as ErrCartesianJoin,
+	// use a CROSS JOIN for an intended cartesian product
+	StrictJoin bool
+
+	// StrictWindowColumns check PARTITION BY and ORDER BY columns of selected window functions
+	// against the schema of the query, only columns of the queried table are checked
+	StrictWindowColumns bool
+
+	// StrictTyping report a condition comparing a typed column against a literal of another type,
+	// e.g. field.Field(stringField).Gt(5), see field.CheckTyping
+	StrictTyping bool
 }
 
 // Apply update config to new config
@@ -1,12 +1,18 @@
 package gen
 
 import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"gorm.io/datatypes"
 	"gorm.io/gorm"
+	"gorm.io/gorm/callbacks"
 	"gorm.io/gorm/clause"
 	"gorm.io/hints"
 
@@ -61,6 +67,9 @@ func build(stmt *gorm.Statement, opts ...stmtOpt) *gorm.Statement {
 	}
 
 	findClauses := func() []string {
+		if len(stmt.BuildClauses) > 0 {
+			return stmt.BuildClauses
+		}
 		for _, cs := range [][]string{createClauses, queryClauses, updateClauses, deleteClauses} {
 			if _, ok := stmt.Clauses[cs[0]]; ok {
 				return cs
@@ -74,6 +83,14 @@ func build(stmt *gorm.Statement, opts ...stmtOpt) *gorm.Statement {
 }
 
 func TestDO_methods(t *testing.T) {
+	name, famous := "tom", true
+	asOf := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	pgDB, _ := gorm.Open(namedDialectors{name: "postgres"}, &gorm.Config{DryRun: true})
+	var pg DO
+	pg.UseDB(pgDB)
+	pg.UseModel(User{})
+
 	testcases := []struct {
 		Expr         SubQuery
 		Opts         []stmtOpt
@@ -159,6 +176,40 @@ func TestDO_methods(t *testing.T) {
 			Expr:   u.Where(u.Name.Substr(1, 6)),
 			Result: "WHERE SUBSTR(`name`,1,6)",
 		},
+		{
+			Expr: u.WhereFromStruct(struct {
+				Name    *string
+				Age     *int
+				Famous  *bool
+				Address string
+			}{Name: &name, Famous: &famous}, map[string]field.Expr{"Name": u.Name, "Age": u.Age, "Famous": u.Famous}),
+			ExpectedVars: []interface{}{"tom", true},
+			Result:       "WHERE `name` = ? AND `famous` = ?",
+		},
+		{
+			Expr:         u.Where(u.ID.Gt(1)).WhereFromStruct(&struct{ Age *int }{}, map[string]field.Expr{"Age": u.Age}),
+			ExpectedVars: []interface{}{uint(1)},
+			Result:       "WHERE `id` > ?",
+		},
+		{
+			Expr:         u.DO.Where(u.ID.Eq(1)).(*DO).ExcludeSoftDeleted(field.NewTime("", "deleted_at")),
+			ExpectedVars: []interface{}{uint(1)},
+			Result:       "WHERE `id` = ? AND `deleted_at` IS NULL",
+		},
+		{
+			Expr:         u.DO.ExcludeSoftDeleted(field.NewTime("", "deleted_at")).Where(u.ID.Eq(1)).(*DO).IncludeSoftDeleted(),
+			ExpectedVars: []interface{}{uint(1)},
+			Result:       "WHERE `id` = ?",
+		},
+		{
+			Expr:   u.DO.ExcludeSoftDeleted(field.NewTime("", "deleted_at")).(*DO).IncludeSoftDeleted(),
+			Result: "",
+		},
+		{
+			Expr:         u.DO.Where(u.ID.Eq(1)).(*DO).AsOf(asOf, field.NewTime("", "valid_from"), field.NewTime("", "valid_to")),
+			ExpectedVars: []interface{}{uint(1), asOf, asOf},
+			Result:       "WHERE `id` = ? AND `valid_from` <= ? AND `valid_to` > ?",
+		},
 		{
 			Expr:         u.Where(u.Name.Eq("tom"), u.Age.Gt(18)),
 			ExpectedVars: []interface{}{"tom", 18},
@@ -192,6 +243,86 @@ func TestDO_methods(t *testing.T) {
 			Expr:   u.Order(u.ID.Asc()).Order(u.Age),
 			Result: "ORDER BY `id` ASC,`age`",
 		},
+		{
+			Expr:   u.DO.Select(u.Name, u.Score.Sum().As("total")).Group(u.Name).(*DO).OrderByAlias("total", true),
+			Opts:   []stmtOpt{withFROM},
+			Result: "SELECT `name`,SUM(`score`) AS `total` FROM `users_info` GROUP BY `name` ORDER BY `total` DESC",
+		},
+		{
+			Expr:   u.DO.Order(u.Name).(*DO).OrderByAlias("total", false),
+			Result: "ORDER BY `name`,`total`",
+		},
+		{
+			Expr:         u.DO.Where(u.ID.Eq(1)).(*DO).LockParent(),
+			ExpectedVars: []interface{}{uint(1)},
+			Result:       "WHERE `id` = ? FOR NO KEY UPDATE",
+		},
+		{
+			Expr:   u.DO.Lock("share"),
+			Result: "FOR SHARE",
+		},
+		{
+			Expr:   u.DO.Order(u.Age.Desc()).(*DO).StableOrder(u.ID),
+			Result: "ORDER BY `age` DESC,`id`",
+		},
+		{
+			Expr:   u.DO.Order(u.Age.Desc(), u.ID.Desc()).(*DO).StableOrder(u.ID),
+			Result: "ORDER BY `age` DESC,`id` DESC",
+		},
+		{
+			Expr:   u.DO.Order(u.Age).(*DO).StableOrder(u.ID).(*DO).StableOrder(u.ID),
+			Result: "ORDER BY `age`,`id`",
+		},
+		{
+			Expr:   u.DO.StableOrder(u.ID),
+			Result: "ORDER BY `id`",
+		},
+		{
+			Expr:         u.DO.LimitSafe(10).(*DO).OffsetSafe(20),
+			ExpectedVars: []interface{}{10, 20},
+			Result:       "LIMIT ? OFFSET ?",
+		},
+		{
+			Expr:         u.DO.LimitSafe(0),
+			ExpectedVars: []interface{}{0},
+			Result:       "LIMIT ?",
+		},
+		{
+			Expr:         u.DO.Offset(20).(*DO).FetchFirst(10, false),
+			ExpectedVars: []interface{}{20, 10},
+			Result:       "OFFSET ? ROWS FETCH FIRST ? ROWS ONLY",
+		},
+		{
+			Expr:         u.DO.Where(u.Famous.Is(true)).(*DO).Limit(10).(*DO).Offset(20).(*DO).AutoKeyset([]field.OrderExpr{u.Age, u.ID}, u.ID),
+			Opts:         []stmtOpt{withFROM},
+			ExpectedVars: []interface{}{true, true, 1, 19, 10},
+			Result: "FROM `users_info` WHERE `famous` = ? AND (`age`,`id`) > " +
+				"(SELECT `age`,`id` FROM `users_info` WHERE `famous` = ? ORDER BY `age`,`id` LIMIT ? OFFSET ?) ORDER BY `age`,`id` LIMIT ?",
+		},
+		{
+			Expr:         u.DO.Limit(10).(*DO).Offset(20).(*DO).AutoKeyset([]field.OrderExpr{u.Score.Desc().(field.OrderExpr), u.ID.Desc().(field.OrderExpr)}, u.ID),
+			Opts:         []stmtOpt{withFROM},
+			ExpectedVars: []interface{}{1, 19, 10},
+			Result: "FROM `users_info` WHERE (`score`,`id`) < " +
+				"(SELECT `score`,`id` FROM `users_info` ORDER BY `score` DESC,`id` DESC LIMIT ? OFFSET ?) ORDER BY `score` DESC,`id` DESC LIMIT ?",
+		},
+		{
+			Expr:         u.DO.Limit(10).(*DO).Offset(20).(*DO).AutoKeyset([]field.OrderExpr{u.Age}, u.ID),
+			Opts:         []stmtOpt{withFROM},
+			ExpectedVars: []interface{}{10, 20},
+			Result:       "FROM `users_info` ORDER BY `age` LIMIT ? OFFSET ?",
+		},
+		{
+			Expr:         u.DO.Limit(10).(*DO).Offset(20).(*DO).AutoKeyset([]field.OrderExpr{u.Age.Desc().(field.OrderExpr), u.ID}, u.ID),
+			Opts:         []stmtOpt{withFROM},
+			ExpectedVars: []interface{}{10, 20},
+			Result:       "FROM `users_info` ORDER BY `age` DESC,`id` LIMIT ? OFFSET ?",
+		},
+		{
+			Expr:         u.DO.Order(u.Score.Desc()).(*DO).FetchFirst(3, true),
+			ExpectedVars: []interface{}{3},
+			Result:       "ORDER BY `score` DESC FETCH FIRST ? ROWS WITH TIES",
+		},
 		{
 			Expr:   u.Clauses(hints.New("hint")).Select(),
 			Result: "SELECT /*+ hint */ *",
@@ -355,6 +486,11 @@ func TestDO_methods(t *testing.T) {
 			ExpectedVars: []interface{}{100.0},
 			Result:       "SELECT `id` WHERE (`id`,`age`) IN (SELECT `id`,`age` FROM `users_info` WHERE `score` = ?)",
 		},
+		{
+			Expr:         u.Where(u.Famous.Is(true), RowInSubQuery([]field.Expr{u.ID, u.Age}, u.Select(u.ID, u.Age).Where(u.Score.Eq(100)))).Where(u.Name.Eq("tom")),
+			ExpectedVars: []interface{}{true, 100.0, "tom"},
+			Result:       "WHERE `famous` = ? AND (`id`,`age`) IN (SELECT `id`,`age` FROM `users_info` WHERE `score` = ?) AND `name` = ?",
+		},
 		{
 			Expr:         u.Select(u.Age.Avg().As("avgage")).Group(u.Name).Having(u.Columns(u.Age.Avg()).Gt(u.Select(u.Age.Avg()).Where(u.Name.Like("name%")))),
 			Opts:         []stmtOpt{withFROM},
@@ -365,7 +501,100 @@ func TestDO_methods(t *testing.T) {
 			Expr:   student.Select().LeftJoin(teacher, teacher.ID.EqCol(student.Instructor)).Group(student.ID),
 			Result: "SELECT * FROM `student` LEFT JOIN `teacher` ON `teacher`.`id` = `student`.`instructor` GROUP BY `student`.`id`",
 		},
+		{
+			Expr:         u.Select(u.RegisterAt.DateTrunc("day").As("day"), u.ID.Count()).Group(u.RegisterAt.DateTrunc("day").As("day")),
+			Opts:         []stmtOpt{withFROM},
+			ExpectedVars: []interface{}{"day", "day"},
+			Result:       "SELECT DATE_TRUNC(?,`register_at`) AS `day`,COUNT(`id`) FROM `users_info` GROUP BY DATE_TRUNC(?,`register_at`)",
+		},
+		{
+			Expr:   u.Select(u.Name.Upper().As("name"), u.ID.Count()).Group(u.Name.Upper().As("name")),
+			Opts:   []stmtOpt{withFROM},
+			Result: "SELECT UPPER(`name`) AS `name`,COUNT(`id`) FROM `users_info` GROUP BY UPPER(`name`)",
+		},
+		{
+			Expr:   u.Select(u.Name.As("n"), u.ID.Count()).Group(u.Name.As("n")),
+			Opts:   []stmtOpt{withFROM},
+			Result: "SELECT `name` AS `n`,COUNT(`id`) FROM `users_info` GROUP BY `name`",
+		},
+		{
+			Expr:         u.DO.Select(u.RegisterAt.DateTrunc("day").As("day"), u.ID.Count()).(*DO).GroupByAlias("day"),
+			Opts:         []stmtOpt{withFROM},
+			ExpectedVars: []interface{}{"day"},
+			Result:       "SELECT DATE_TRUNC(?,`register_at`) AS `day`,COUNT(`id`) FROM `users_info` GROUP BY `day`",
+		},
+		{
+			Expr:         u.DO.Select(u.RegisterAt.DateTrunc("day"), u.ID.Count()).Group(u.RegisterAt.DateTrunc("day"), nil),
+			Opts:         []stmtOpt{withFROM},
+			Result:       "SELECT DATE_TRUNC(?,`register_at`),COUNT(`id`) FROM `users_info` GROUP BY DATE_TRUNC(?,`register_at`)",
+			ExpectedVars: []interface{}{"day", "day"},
+		},
+		{
+			Expr:   student.CrossJoin("teacher").Select(),
+			Result: "SELECT * FROM `student` CROSS JOIN `teacher`",
+		},
+		{
+			Expr:         student.CrossJoinLateral(teacher.Select(teacher.Name).Where(teacher.ID.EqCol(student.Instructor), teacher.Name.Neq("")), "t").Select(),
+			ExpectedVars: []interface{}{""},
+			Result:       "SELECT * FROM `student` CROSS JOIN LATERAL (SELECT `teacher`.`name` FROM `teacher` WHERE `teacher`.`id` = `student`.`instructor` AND `teacher`.`name` <> ?) AS `t`",
+		},
+		{
+			Expr:   student.CrossJoinLateralFunc(field.NewField("student", "tags").UnnestWithOrdinality(), "t", "tag", "ord").Select(field.NewString("t", "tag"), field.NewInt("t", "ord")),
+			Result: "SELECT `t`.`tag`,`t`.`ord` FROM `student` CROSS JOIN LATERAL UNNEST(`student`.`tags`) WITH ORDINALITY AS `t`(`tag`,`ord`)",
+		},
+		{
+			Expr:   student.CrossJoinLateralFunc(field.NewField("student", "tags").Unnest(), "tag").Select(),
+			Result: "SELECT * FROM `student` CROSS JOIN LATERAL UNNEST(`student`.`tags`) AS `tag`",
+		},
+		{
+			Expr: pg.CountByConditions(map[string]field.Expr{
+				"adult":  u.Age.Gte(18),
+				"active": u.Name.Neq(""),
+				"top":    u.Score.Gt(90),
+			}),
+			Opts:         []stmtOpt{withFROM},
+			ExpectedVars: []interface{}{"", 18, 90.0},
+			Result: "SELECT COUNT(*) FILTER (WHERE `name` <> ?) AS `active`,COUNT(*) FILTER (WHERE `age` >= ?) AS `adult`," +
+				"COUNT(*) FILTER (WHERE `score` > ?) AS `top` FROM `users_info`",
+		},
+		{
+			Expr:         pg.RatePerGroup(u.Score.Gte(60), []field.Expr{u.Age, u.Famous}),
+			Opts:         []stmtOpt{withFROM},
+			ExpectedVars: []interface{}{60.0},
+			Result:       "SELECT `age`,`famous`,COUNT(*) FILTER (WHERE `score` >= ?)::float / NULLIF(COUNT(*), 0) AS `rate` FROM `users_info` GROUP BY `age`,`famous`",
+		},
+		{
+			Expr: u.DO.Select(u.Score.FirstValue().OverNamed("w").As("first"), u.Score.WindowSum().OverNamed("w").As("total")).(*DO).
+				DefineWindow("w", field.WindowSpec{PartitionBy: []field.Expr{u.Name}, OrderBy: []field.Expr{u.ID}}),
+			Opts:   []stmtOpt{withFROM},
+			Result: "SELECT FIRST_VALUE(`score`) OVER `w` AS `first`,SUM(`score`) OVER `w` AS `total` FROM `users_info` WINDOW `w` AS (PARTITION BY `name` ORDER BY `id`)",
+		},
+		{
+			Expr: u.DO.Select(u.Name, u.Score.Sum().WindowSum().OverNamed("w")).Where(u.Age.Gt(18)).(*DO).
+				DefineWindow("w", field.WindowSpec{OrderBy: []field.Expr{u.Name}}).Group(u.Name).Having(u.Score.Sum().Gt(60)).(*DO).
+				DefineWindow("v", field.WindowSpec{Frame: &field.FrameSpec{Type: field.FrameRows, Start: field.FrameBound{Type: field.Preceding, Offset: 1}}}).
+				Order(u.Name),
+			Opts:         []stmtOpt{withFROM},
+			ExpectedVars: []interface{}{18, 60.0, 1},
+			Result: "SELECT `name`,SUM(SUM(`score`)) OVER `w` FROM `users_info` WHERE `age` > ? GROUP BY `name` HAVING SUM(`score`) > ? " +
+				"WINDOW `w` AS (ORDER BY `name`),`v` AS (ROWS ? PRECEDING) ORDER BY `name`",
+		},
 		// ======================== from subquery ========================
+		{
+			Expr:         u.DO.Select(u.Name, u.Score.Sum().As("total")).Where(u.Age.Gt(18)).Group(u.Name).Order(u.Name).(*DO).WithGrandTotal(nil, u.Score.Sum()).Select(),
+			Opts:         []stmtOpt{withFROM},
+			ExpectedVars: []interface{}{18, 18},
+			Result: "SELECT * FROM ((SELECT `name`,SUM(`score`) AS `total` FROM `users_info` WHERE `age` > ? GROUP BY `name` ORDER BY `name`) " +
+				"UNION ALL (SELECT NULL,SUM(`score`) FROM `users_info` WHERE `age` > ?)) AS `users_info`",
+		},
+		{
+			Expr: u.DO.Select(u.Name, u.Score.Sum().As("total")).Where(u.Age.Gt(18)).Group(u.Name).Having(u.Score.Sum().Gt(60)).(*DO).
+				Limit(10).(*DO).WithGrandTotal(nil, u.Score.Sum()).Select(),
+			Opts:         []stmtOpt{withFROM},
+			ExpectedVars: []interface{}{18, 60.0, 10, 18},
+			Result: "SELECT * FROM ((SELECT `name`,SUM(`score`) AS `total` FROM `users_info` WHERE `age` > ? GROUP BY `name` HAVING SUM(`score`) > ? LIMIT ?) " +
+				"UNION ALL (SELECT NULL,SUM(`score`) FROM `users_info` WHERE `age` > ?)) AS `users_info`",
+		},
 		{
 			Expr:         Table(u.Select(u.ID, u.Name).Where(u.Age.Gt(18))).Select(),
 			Opts:         []stmtOpt{withFROM},
@@ -390,6 +619,12 @@ func TestDO_methods(t *testing.T) {
 			ExpectedVars: []interface{}{18, 100.0},
 			Result:       "SELECT * FROM (SELECT * FROM `users_info` WHERE `age` > ?) AS `a`, (SELECT * FROM `users_info` WHERE `score` >= ?) AS `b`",
 		},
+		{
+			Expr:         Table(NamedSubQuery(u.Select(u.ID, u.Name).Where(u.Age.Gt(18)), "t")).Select(RowToJSON("t")),
+			Opts:         []stmtOpt{withFROM},
+			ExpectedVars: []interface{}{18},
+			Result:       "SELECT row_to_json(`t`) FROM (SELECT `id`,`name` FROM `users_info` WHERE `age` > ?) AS `t`",
+		},
 
 		// ======================== join subquery ========================
 		{
@@ -423,3 +658,728 @@ func TestDO_methods(t *testing.T) {
 		checkBuildExpr(t, testcase.Expr, testcase.Opts, testcase.Result, testcase.ExpectedVars)
 	}
 }
+
+func TestDO_WhereFromStruct_error(t *testing.T) {
+	filters := struct{ Name *string }{}
+
+	if err := u.WhereFromStruct(filters, map[string]field.Expr{"Nickname": u.Name}).underlyingDB().Error; err == nil {
+		t.Errorf("expect error for unknown mapping, got nil")
+	}
+	if err := u.WhereFromStruct(nil, map[string]field.Expr{"Name": u.Name}).underlyingDB().Error; err == nil {
+		t.Errorf("expect error for non-struct filters, got nil")
+	}
+	if err := u.WhereFromStruct(filters, map[string]field.Expr{"Name": u.Name}).underlyingDB().Error; err != nil {
+		t.Errorf("expect no error, got %s", err)
+	}
+}
+
+func TestDO_LimitSafe_negative(t *testing.T) {
+	if err := u.DO.LimitSafe(-1).underlyingDB().Error; !errors.Is(err, ErrNegativeLimit) {
+		t.Errorf("expect ErrNegativeLimit for negative limit, got %v", err)
+	}
+	if err := u.DO.OffsetSafe(-1).underlyingDB().Error; !errors.Is(err, ErrNegativeLimit) {
+		t.Errorf("expect ErrNegativeLimit for negative offset, got %v", err)
+	}
+	if err := u.DO.LimitSafe(0).(*DO).OffsetSafe(0).underlyingDB().Error; err != nil {
+		t.Errorf("expect no error, got %s", err)
+	}
+}
+
+func TestDO_FetchFirstPercent(t *testing.T) {
+	oracleDB, _ := gorm.Open(oracleDialectors{}, &gorm.Config{DryRun: true})
+
+	var do DO
+	do.UseDB(oracleDB)
+	do.UseModel(User{})
+
+	checkBuildExpr(t, do.FetchFirstPercent(10, false), nil, "FETCH FIRST ? PERCENT ROWS ONLY", []interface{}{10.0})
+	checkBuildExpr(t, do.FetchFirstPercent(12.5, true), nil, "FETCH FIRST ? PERCENT ROWS WITH TIES", []interface{}{12.5})
+
+	if err := u.DO.FetchFirstPercent(10, false).underlyingDB().Error; !errors.Is(err, ErrUnsupportedDialect) {
+		t.Errorf("expect ErrUnsupportedDialect for mysql, got %v", err)
+	}
+}
+
+func TestDO_Group_postgres(t *testing.T) {
+	pgDB, _ := gorm.Open(postgresDialectors{}, &gorm.Config{DryRun: true})
+
+	var do DO
+	do.UseDB(pgDB)
+	do.UseModel(User{})
+
+	grouped := do.Where(u.Age.Gt(18)).Group(u.RegisterAt.DateTrunc("day").As("day"), u.Name).Having(u.ID.Count().Gt(1)).(*DO).GroupByAlias("week")
+	checkBuildExpr(t, grouped, nil,
+		"WHERE `age` > $1 GROUP BY DATE_TRUNC($2,`register_at`),`name`,`week` HAVING COUNT(`id`) > $3",
+		[]interface{}{18, "day", 1})
+}
+
+func TestDO_CountByConditions_unsupported(t *testing.T) {
+	err := u.DO.CountByConditions(map[string]field.Expr{"adult": u.Age.Gte(18)}).underlyingDB().Error
+	if !errors.Is(err, ErrUnsupportedDialect) {
+		t.Errorf("expect ErrUnsupportedDialect for mysql, got %v", err)
+	}
+}
+
+func TestDO_RatePerGroup_unsupported(t *testing.T) {
+	err := u.DO.RatePerGroup(u.Score.Gte(60), []field.Expr{u.Age}).underlyingDB().Error
+	if !errors.Is(err, ErrUnsupportedDialect) {
+		t.Errorf("expect ErrUnsupportedDialect for mysql, got %v", err)
+	}
+}
+
+func TestDO_OrderByCustom(t *testing.T) {
+	order := []interface{}{"pending", "active", "closed"}
+	checkBuildExpr(t, u.DO.OrderByCustom(u.Name, order).(*DO).StableOrder(u.ID), nil,
+		"ORDER BY FIELD(`name`,\"pending\",\"active\",\"closed\"),`id`", nil)
+
+	pgDB, _ := gorm.Open(postgresDialectors{}, &gorm.Config{DryRun: true})
+
+	var do DO
+	do.UseDB(pgDB)
+	do.UseModel(User{})
+
+	checkBuildExpr(t, do.OrderByCustom(u.Name, order), nil, "ORDER BY array_position(ARRAY['pending','active','closed'], `name`)", nil)
+
+	if err := student.DO.OrderByCustom(u.Name, nil).underlyingDB().Error; err != nil {
+		t.Errorf("expect empty order ignored, got %v", err)
+	}
+
+	var oracle DO
+	oracleDB, _ := gorm.Open(oracleDialectors{}, &gorm.Config{DryRun: true})
+	oracle.UseDB(oracleDB)
+	oracle.UseModel(User{})
+	if err := oracle.OrderByCustom(u.Name, order).underlyingDB().Error; !errors.Is(err, ErrUnsupportedDialect) {
+		t.Errorf("expect ErrUnsupportedDialect for oracle, got %v", err)
+	}
+}
+
+func TestDO_DateSpine(t *testing.T) {
+	from, to := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC)
+	pgDB, _ := gorm.Open(namedDialectors{name: "postgres"}, &gorm.Config{DryRun: true})
+	var do DO
+	do.UseDB(pgDB)
+	do.UseModel(User{})
+	spine := do.DateSpine(from, to, "1 day")
+
+	sql, vars := buildWithQuery(spine.(*DO))
+	expected := "SELECT `day`::date AS `day` FROM generate_series(?, ?, ?::interval) AS `spine`(`day`)"
+	if sql != expected {
+		t.Errorf("SQL expects: %s\ngot: %s", expected, sql)
+	}
+	if expectedVars := []interface{}{from, to, "1 day"}; !reflect.DeepEqual(vars, expectedVars) {
+		t.Errorf("Vars expects: %v got: %v", expectedVars, vars)
+	}
+
+	day := field.NewTime("s", "day")
+	sql, _ = buildWithQuery(Table(NamedSubQuery(spine, "s")).Select(day))
+	expected = "SELECT `s`.`day` FROM (SELECT `day`::date AS `day` FROM generate_series(?, ?, ?::interval) AS `spine`(`day`)) AS `s`"
+	if sql != expected {
+		t.Errorf("SQL expects: %s\ngot: %s", expected, sql)
+	}
+
+	if err := u.DO.DateSpine(from, to, "1 day").underlyingDB().Error; !errors.Is(err, ErrUnsupportedDialect) {
+		t.Errorf("expect ErrUnsupportedDialect for mysql, got %v", err)
+	}
+}
+
+func TestDO_GroupByAlias_shadowsColumn(t *testing.T) {
+	do := u.DO.Select(u.Name.Upper().As("name"), u.ID.Count()).(*DO)
+	if err := do.GroupByAlias("name").underlyingDB().Error; !errors.Is(err, ErrAliasShadowsColumn) {
+		t.Errorf("expect ErrAliasShadowsColumn for alias of a column name, got %v", err)
+	}
+	if err := do.GroupByAlias("upper_name").underlyingDB().Error; err != nil {
+		t.Errorf("expect no error for alias not shadowing a column, got %s", err)
+	}
+}
+
+func TestDO_StrictJoin(t *testing.T) {
+	do := student.DO
+	do.DOConfig = &DOConfig{StrictJoin: true}
+
+	if err := do.Join(teacher, field.EmptyExpr()).underlyingDB().Error; !errors.Is(err, ErrCartesianJoin) {
+		t.Errorf("expect ErrCartesianJoin for join without ON, got %v", err)
+	}
+	if err := do.Join(teacher, teacher.ID.EqCol(student.Instructor)).underlyingDB().Error; err != nil {
+		t.Errorf("expect no error for join with ON, got %s", err)
+	}
+	if err := student.DO.Join(teacher, field.EmptyExpr()).underlyingDB().Error; err != nil {
+		t.Errorf("expect no error when strict join is disabled, got %s", err)
+	}
+
+	crossJoin := clause.Join{Type: clause.CrossJoin, Table: clause.Table{Name: "teacher"}}
+	if err := checkCartesianJoins([]clause.Join{crossJoin}); err != nil {
+		t.Errorf("expect no error for explicit cross join, got %s", err)
+	}
+}
+
+func TestDO_bindVarsBatchSize(t *testing.T) {
+	users := make([]*User, 5)
+
+	testcases := []struct {
+		MaxBindVars int
+		BatchSize   int
+		Expected    int
+		Err         error
+	}{
+		{MaxBindVars: 0, BatchSize: 0, Expected: 0},
+		{MaxBindVars: 0, BatchSize: 100, Expected: 100},
+		{MaxBindVars: 35, BatchSize: 0, Expected: 0},
+		{MaxBindVars: 20, BatchSize: 0, Expected: 2},
+		{MaxBindVars: 20, BatchSize: 1, Expected: 1},
+		{MaxBindVars: 20, BatchSize: 3, Expected: 2},
+		{MaxBindVars: 6, BatchSize: 0, Err: ErrTooManyBindVars},
+	}
+
+	for _, testcase := range testcases {
+		do := u.DO
+		do.DOConfig = &DOConfig{MaxBindVars: testcase.MaxBindVars}
+
+		size, err := do.bindVarsBatchSize(users, testcase.BatchSize)
+		if !errors.Is(err, testcase.Err) {
+			t.Errorf("error expects %v got %v", testcase.Err, err)
+		}
+		if size != testcase.Expected {
+			t.Errorf("batch size expects %d got %d", testcase.Expected, size)
+		}
+	}
+}
+
+func TestDO_Create_chunked(t *testing.T) {
+	testDB, _ := gorm.Open(mysqlDialectors{}, &gorm.Config{DryRun: true, SkipDefaultTransaction: true})
+
+	var chunks []int
+	_ = testDB.Callback().Create().After("gorm:create").Register("test:chunks", func(tx *gorm.DB) {
+		chunks = append(chunks, tx.Statement.ReflectValue.Len())
+		if len(tx.Statement.Vars) > 20 {
+			t.Errorf("bind vars expects no more than %d got %d", 20, len(tx.Statement.Vars))
+		}
+	})
+
+	var do DO
+	do.UseDB(testDB, &DOConfig{MaxBindVars: 20})
+	do.UseModel(User{})
+
+	users := make([]*User, 5)
+	for i := range users {
+		users[i] = &User{ID: uint(i + 1), Name: "tom"}
+	}
+	if err := do.Create(users); err != nil {
+		t.Fatalf("create fail: %s", err)
+	}
+	if !reflect.DeepEqual(chunks, []int{2, 2, 1}) {
+		t.Errorf("chunks expects %v got %v", []int{2, 2, 1}, chunks)
+	}
+}
+
+type capturedSQL struct {
+	SQL  string
+	Vars []interface{}
+}
+
+// captureDB open a dry run db which records the sql of the last update/delete
+func captureDB() (*gorm.DB, *capturedSQL) {
+	testDB, _ := gorm.Open(mysqlDialectors{}, &gorm.Config{DryRun: true, SkipDefaultTransaction: true})
+
+	captured := new(capturedSQL)
+	capture := func(tx *gorm.DB) { *captured = capturedSQL{SQL: tx.Statement.SQL.String(), Vars: tx.Statement.Vars} }
+	_ = testDB.Callback().Update().After("gorm:update").Register("test:capture", capture)
+	_ = testDB.Callback().Delete().After("gorm:delete").Register("test:capture", capture)
+	_ = testDB.Callback().Raw().After("gorm:raw").Register("test:capture", capture)
+	_ = testDB.Callback().Query().After("gorm:query").Register("test:capture", capture)
+	return testDB, captured
+}
+
+func TestDO_DefineWindow_query(t *testing.T) {
+	testDB, stmt := captureDB()
+
+	var do DO
+	do.UseDB(testDB)
+	do.UseModel(StudentRaw{})
+
+	_, err := do.Select(student.Instructor, student.Age.Max().WindowSum().OverNamed("w").As("total")).
+		Group(student.Instructor).Having(student.Age.Max().Gt(18)).(*DO).
+		DefineWindow("w", field.WindowSpec{OrderBy: []field.Expr{student.Instructor}}).
+		Order(student.Instructor).Find()
+	if err != nil {
+		t.Fatalf("query fail: %s", err)
+	}
+	expected := "SELECT `student`.`instructor`,SUM(MAX(`student`.`age`)) OVER `w` AS `total` FROM `student` GROUP BY `student`.`instructor` " +
+		"HAVING MAX(`student`.`age`) > ? WINDOW `w` AS (ORDER BY `student`.`instructor`) ORDER BY `student`.`instructor`"
+	if stmt.SQL != expected {
+		t.Errorf("SQL expects %s got %s", expected, stmt.SQL)
+	}
+}
+
+func TestDO_assignGenerated(t *testing.T) {
+	testDB, stmt := captureDB()
+
+	var do DO
+	do.UseDB(testDB)
+	do.UseModel(StudentRaw{})
+
+	total := field.NewGenerated("student", "total")
+	expected := "generated column total can not be assigned"
+	check := func(name string, err error) {
+		if err == nil || err.Error() != expected {
+			t.Errorf("%s expects error %q, got %v", name, expected, err)
+		}
+	}
+
+	_, err := do.Where(student.ID.Eq(1)).UpdateSimple(student.Age.Value(18), total.Value(nil))
+	check("UpdateSimple", err)
+	_, err = do.Where(student.ID.Eq(1)).Update(total, total.Null())
+	check("Update", err)
+	_, err = do.Where(student.ID.Eq(1)).UpdateColumns(Assignments(total.SetCol(student.Age)))
+	check("UpdateColumns", err)
+	check("Attrs", do.Attrs(total.Value(nil)).underlyingDB().Error)
+	check("Assign", do.Assign(total.Value(nil)).underlyingDB().Error)
+	check("OnlyIfChanged", do.OnlyIfChanged(field.SetIfChanged(total, 1)).underlyingDB().Error)
+	if stmt.SQL != "" {
+		t.Errorf("expect no statement executed, got %s", stmt.SQL)
+	}
+}
+
+func TestDO_UpdateFromSubQuery(t *testing.T) {
+	testDB, stmt := captureDB()
+
+	var do DO
+	do.UseDB(testDB)
+	do.UseModel(StudentRaw{})
+
+	source := teacher.Select(teacher.ID, teacher.Name).Where(teacher.Name.Neq("")).As("t")
+	tID, tName := field.NewInt64("t", "id"), field.NewString("t", "name")
+
+	err := do.UpdateFromSubQuery(source, field.And(student.Instructor.EqCol(tID), student.Age.Lt(30)),
+		student.Name.SetCol(tName), student.Age.Value(18))
+	if err != nil {
+		t.Fatalf("update fail: %s", err)
+	}
+
+	expected := "UPDATE `student` SET `name`=`t`.`name`,`age`=? FROM (SELECT `teacher`.`id`,`teacher`.`name` FROM `teacher` WHERE `teacher`.`name` <> ?) AS `t` " +
+		"WHERE `student`.`instructor` = `t`.`id` AND `student`.`age` < ?"
+	if sql := stmt.SQL; sql != expected {
+		t.Errorf("SQL expects: %s\ngot: %s", expected, sql)
+	}
+	if expectedVars := []interface{}{18, "", 30}; !reflect.DeepEqual(stmt.Vars, expectedVars) {
+		t.Errorf("Vars expects: %v got: %v", expectedVars, stmt.Vars)
+	}
+}
+
+func TestDO_DeleteUsing(t *testing.T) {
+	testDB, stmt := captureDB()
+
+	var do DO
+	do.UseDB(testDB)
+	do.UseModel(StudentRaw{})
+
+	source := teacher.Select(teacher.ID).Where(teacher.Name.Eq("tom")).As("t")
+	err := do.DeleteUsing(source, field.And(student.Instructor.EqCol(field.NewInt64("t", "id")), student.Age.Gt(30)))
+	if err != nil {
+		t.Fatalf("delete fail: %s", err)
+	}
+
+	expected := "DELETE FROM `student` USING (SELECT `teacher`.`id` FROM `teacher` WHERE `teacher`.`name` = ?) AS `t` " +
+		"WHERE `student`.`instructor` = `t`.`id` AND `student`.`age` > ?"
+	if sql := stmt.SQL; sql != expected {
+		t.Errorf("SQL expects: %s\ngot: %s", expected, sql)
+	}
+	if expectedVars := []interface{}{"tom", 30}; !reflect.DeepEqual(stmt.Vars, expectedVars) {
+		t.Errorf("Vars expects: %v got: %v", expectedVars, stmt.Vars)
+	}
+}
+
+func TestDO_MaterializeInto(t *testing.T) {
+	testDB, stmt := captureDB()
+
+	var do DO
+	do.UseDB(testDB)
+	do.UseModel(User{})
+
+	report := do.Select(u.Name, u.Score.WindowSum().Over(field.WindowSpec{PartitionBy: []field.Expr{u.Name}, OrderBy: []field.Expr{u.ID}}).As("total")).
+		Where(u.Age.Gt(18)).(*DO)
+	if err := report.MaterializeInto("report.user_total"); err != nil {
+		t.Fatalf("materialize fail: %s", err)
+	}
+
+	expected := "CREATE TABLE `report`.`user_total` AS SELECT `name`,SUM(`score`) OVER (PARTITION BY `name` ORDER BY `id`) AS `total` FROM `users_info` WHERE `age` > ?"
+	if sql := stmt.SQL; sql != expected {
+		t.Errorf("SQL expects: %s\ngot: %s", expected, sql)
+	}
+	if expectedVars := []interface{}{18}; !reflect.DeepEqual(stmt.Vars, expectedVars) {
+		t.Errorf("Vars expects: %v got: %v", expectedVars, stmt.Vars)
+	}
+
+	if err := report.MaterializeInto("user_total; DROP TABLE users_info"); err == nil {
+		t.Errorf("expect invalid table name error")
+	}
+}
+
+// upsertDriver database/sql driver answering the statements of UpsertReturningInserted with canned rows
+type upsertDriver struct{ executed *[]string }
+
+func (d upsertDriver) Open(string) (driver.Conn, error) { return upsertConn(d), nil }
+
+type upsertConn upsertDriver
+
+func (c upsertConn) Prepare(query string) (driver.Stmt, error) {
+	return upsertStmt{conn: c, query: query}, nil
+}
+func (upsertConn) Close() error              { return nil }
+func (upsertConn) Begin() (driver.Tx, error) { return upsertTx{}, nil }
+
+type upsertTx struct{}
+
+func (upsertTx) Commit() error   { return nil }
+func (upsertTx) Rollback() error { return nil }
+
+type upsertStmt struct {
+	conn  upsertConn
+	query string
+}
+
+func (upsertStmt) Close() error  { return nil }
+func (upsertStmt) NumInput() int { return -1 }
+func (s upsertStmt) Exec([]driver.Value) (driver.Result, error) {
+	*s.conn.executed = append(*s.conn.executed, s.query)
+	return driver.RowsAffected(1), nil
+}
+func (s upsertStmt) Query([]driver.Value) (driver.Rows, error) {
+	*s.conn.executed = append(*s.conn.executed, s.query)
+	if strings.HasPrefix(s.query, "INSERT") {
+		return &upsertRows{columns: []string{"id", "name", "set_config"}, values: []driver.Value{int64(7), "tom", "true"}}, nil
+	}
+	return &upsertRows{columns: []string{"current_setting"}, values: []driver.Value{true}}, nil
+}
+
+type upsertRows struct {
+	columns []string
+	values  []driver.Value
+	done    bool
+}
+
+func (r *upsertRows) Columns() []string { return r.columns }
+func (r *upsertRows) Close() error      { return nil }
+func (r *upsertRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	r.done = true
+	copy(dest, r.values)
+	return nil
+}
+
+type upsertUser struct {
+	ID     uint
+	Name   string
+	hooked bool
+}
+
+func (u *upsertUser) BeforeCreate(*gorm.DB) error {
+	u.hooked = true
+	return nil
+}
+
+func TestDO_UpsertReturningInserted(t *testing.T) {
+	var executed []string
+	sql.Register("gen_upsert", upsertDriver{executed: &executed})
+	sqlDB, _ := sql.Open("gen_upsert", "")
+	testDB, _ := gorm.Open(postgresDialectors{}, &gorm.Config{ConnPool: sqlDB, SkipDefaultTransaction: true})
+
+	var do DO
+	do.UseDB(testDB)
+	do.UseModel(upsertUser{})
+
+	value := &upsertUser{Name: "tom"}
+	inserted, err := do.UpsertReturningInserted(value)
+	if err != nil {
+		t.Fatalf("upsert fail: %s", err)
+	}
+	if !inserted {
+		t.Errorf("expect inserted")
+	}
+	if value.ID != 7 || !value.hooked {
+		t.Errorf("expect hooks called and primary key filled by returned row, got %+v", value)
+	}
+
+	expected := []string{
+		"INSERT INTO `upsert_users` (`name`) VALUES ($1) ON CONFLICT (`id`) DO UPDATE SET `name`=`excluded`.`name` " +
+			"RETURNING *,set_config('gen.upsert_inserted', (xmax = 0)::text, true)",
+		"SELECT current_setting('gen.upsert_inserted')::boolean",
+	}
+	if !reflect.DeepEqual(executed, expected) {
+		t.Errorf("SQL expects: %q\ngot: %q", expected, executed)
+	}
+
+	mysqlDB, _ := gorm.Open(mysqlDialectors{}, &gorm.Config{DryRun: true})
+	var mysqlDO DO
+	mysqlDO.UseDB(mysqlDB)
+	mysqlDO.UseModel(upsertUser{})
+	if _, err := mysqlDO.UpsertReturningInserted(&upsertUser{Name: "tom"}); !errors.Is(err, ErrUnsupportedDialect) {
+		t.Errorf("expect ErrUnsupportedDialect for mysql, got %v", err)
+	}
+}
+
+func TestDO_Scalar(t *testing.T) {
+	testDB, _ := gorm.Open(mysqlDialectors{}, &gorm.Config{SkipDefaultTransaction: true})
+
+	var (
+		executed string
+		row      interface{}
+	)
+	_ = testDB.Callback().Query().Replace("gorm:query", func(tx *gorm.DB) {
+		callbacks.BuildQuerySQL(tx)
+		executed = tx.Statement.SQL.String()
+		if row == nil {
+			return
+		}
+		dest := reflect.ValueOf(tx.Statement.Dest).Elem()
+		elem := reflect.New(dest.Type().Elem())
+		_ = elem.Interface().(sql.Scanner).Scan(row)
+		dest.Set(reflect.Append(dest, elem.Elem()))
+		tx.RowsAffected = 1
+	})
+
+	var do DO
+	do.UseDB(testDB)
+	do.UseModel(User{})
+
+	row = 12.5
+	if total, err := do.ScalarFloat(u.Score.Sum()); err != nil || total != 12.5 {
+		t.Errorf("ScalarFloat got %v, %v", total, err)
+	}
+	if expected := "SELECT SUM(`score`) FROM `users_info` LIMIT ?"; executed != expected {
+		t.Errorf("SQL expects: %s\ngot: %s", expected, executed)
+	}
+
+	row = int64(3)
+	if count, err := do.Where(u.Age.Gt(18)).(*DO).ScalarInt(u.ID.Count()); err != nil || count != 3 {
+		t.Errorf("ScalarInt got %v, %v", count, err)
+	}
+	if expected := "SELECT COUNT(`id`) FROM `users_info` WHERE `age` > ? LIMIT ?"; executed != expected {
+		t.Errorf("SQL expects: %s\ngot: %s", expected, executed)
+	}
+
+	row = "tom"
+	if name, err := do.ScalarString(u.Name.Max()); err != nil || name != "tom" {
+		t.Errorf("ScalarString got %q, %v", name, err)
+	}
+
+	row = nil
+	if total, err := do.ScalarFloat(u.Score.Sum()); !errors.Is(err, sql.ErrNoRows) || total != 0 {
+		t.Errorf("ScalarFloat of no rows got %v, %v", total, err)
+	}
+	if count, err := do.ScalarInt(u.ID.Count()); !errors.Is(err, sql.ErrNoRows) || count != 0 {
+		t.Errorf("ScalarInt of no rows got %v, %v", count, err)
+	}
+	if name, err := do.ScalarString(u.Name.Max()); !errors.Is(err, sql.ErrNoRows) || name != "" {
+		t.Errorf("ScalarString of no rows got %q, %v", name, err)
+	}
+}
+
+func TestDO_StrictTyping(t *testing.T) {
+	do := u.DO
+	do.DOConfig = &DOConfig{StrictTyping: true}
+
+	mismatch := field.Field(u.Name).Gt(sql.NullInt64{Int64: 5, Valid: true})
+	if err := do.Where(mismatch).underlyingDB().Error; err == nil || !strings.Contains(err.Error(), "compare string column name with number value 5") {
+		t.Errorf("expect type mismatch error in strict typing mode, got %v", err)
+	}
+	if err := do.Where(u.Age.Gt(18)).(*DO).Having(field.Field(u.Name).Eq(sql.NullString{String: "tom", Valid: true})).underlyingDB().Error; err != nil {
+		t.Errorf("expect no error for matched types, got %s", err)
+	}
+	derived := u.RegisterAt.AgeBetween(u.RegisterAt.DateTrunc("day")).Gt(sql.NullString{String: "1 hour", Valid: true})
+	if err := do.Where(derived).underlyingDB().Error; err != nil {
+		t.Errorf("expect derived expression of unknown type not checked, got %s", err)
+	}
+	if err := do.Where(field.Field(u.Age.Add(1)).Eq(sql.NullString{String: "1", Valid: true})).underlyingDB().Error; err == nil {
+		t.Errorf("expect type mismatch error for number arithmetic compared with string")
+	}
+	if err := u.DO.Where(mismatch).underlyingDB().Error; err != nil {
+		t.Errorf("expect no error when strict typing is disabled, got %s", err)
+	}
+}
+
+func TestDO_StrictWindowColumns(t *testing.T) {
+	do := u.DO
+	do.DOConfig = &DOConfig{StrictWindowColumns: true}
+
+	valid := u.Score.WindowSum().Over(field.WindowSpec{PartitionBy: []field.Expr{u.Name}, OrderBy: []field.Expr{u.RegisterAt.Desc()}})
+	if err := do.Select(valid.As("total")).underlyingDB().Error; err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+
+	unknown := u.Score.WindowSum().Over(field.WindowSpec{PartitionBy: []field.Expr{field.NewString("", "nmae")}})
+	err := do.Select(unknown.As("total")).underlyingDB().Error
+	if err == nil || !strings.Contains(err.Error(), `unknown window column "nmae" of table users_info`) {
+		t.Errorf("expect unknown window column error, got %v", err)
+	}
+	if err := u.DO.Select(unknown.As("total")).underlyingDB().Error; err != nil {
+		t.Errorf("expect no error when strict window columns is disabled, got %s", err)
+	}
+
+	joined := u.Score.WindowSum().Over(field.WindowSpec{OrderBy: []field.Expr{field.NewInt("student", "grade").Asc()}})
+	if err := do.Select(joined).underlyingDB().Error; err != nil {
+		t.Errorf("columns of other tables should be skipped, got %s", err)
+	}
+}
+
+func TestDO_UpdateSimple_default(t *testing.T) {
+	testDB, stmt := captureDB()
+
+	var do DO
+	do.UseDB(testDB)
+	do.UseModel(User{})
+
+	if _, err := do.Where(u.ID.Eq(1)).(*DO).UpdateSimple(u.Age.SetCol(field.Default()), u.Name.Value("tom")); err != nil {
+		t.Fatalf("update fail: %s", err)
+	}
+
+	expected := "UPDATE `users_info` SET `age`=DEFAULT,`name`=? WHERE `id` = ?"
+	if sql := stmt.SQL; sql != expected {
+		t.Errorf("SQL expects: %s\ngot: %s", expected, sql)
+	}
+	if expectedVars := []interface{}{"tom", uint(1)}; !reflect.DeepEqual(stmt.Vars, expectedVars) {
+		t.Errorf("Vars expects: %v got: %v", expectedVars, stmt.Vars)
+	}
+}
+
+func TestDO_Updates_assignments(t *testing.T) {
+	testDB, stmt := captureDB()
+
+	var do DO
+	do.UseDB(testDB)
+	do.UseModel(User{})
+
+	list := Assignments(u.Name.Value("tom"), u.Age.Add(1)).Add(u.Score.Value(100))
+	if _, err := do.Where(u.ID.Eq(1)).(*DO).Updates(list); err != nil {
+		t.Fatalf("update fail: %s", err)
+	}
+
+	expected := "UPDATE `users_info` SET `name`=?,`age`=`age`+?,`score`=? WHERE `id` = ?"
+	if sql := stmt.SQL; sql != expected {
+		t.Errorf("SQL expects: %s\ngot: %s", expected, sql)
+	}
+	if expectedVars := []interface{}{"tom", 1, float64(100), uint(1)}; !reflect.DeepEqual(stmt.Vars, expectedVars) {
+		t.Errorf("Vars expects: %v got: %v", expectedVars, stmt.Vars)
+	}
+
+	_, err := do.Where(u.ID.Eq(1)).(*DO).Updates(list.Add(u.Name.Value("jerry")))
+	if !errors.Is(err, ErrDuplicateAssignment) {
+		t.Errorf("expect ErrDuplicateAssignment, got %v", err)
+	}
+}
+
+func TestDO_OnlyIfChanged(t *testing.T) {
+	testDB, stmt := captureDB()
+
+	var do DO
+	do.UseDB(testDB)
+	do.UseModel(User{})
+
+	assignments := []field.AssignExpr{field.SetIfChanged(u.Name, "tom"), field.SetIfChanged(u.Age, 18), u.Score.Value(100)}
+	if _, err := do.Where(u.ID.Eq(1)).(*DO).OnlyIfChanged(assignments...).(*DO).UpdateSimple(assignments...); err != nil {
+		t.Fatalf("update fail: %s", err)
+	}
+
+	expected := "UPDATE `users_info` SET `name`=?,`age`=?,`score`=? WHERE `id` = ? AND (`name` IS DISTINCT FROM ? OR `age` IS DISTINCT FROM ?)"
+	if sql := stmt.SQL; sql != expected {
+		t.Errorf("SQL expects: %s\ngot: %s", expected, sql)
+	}
+	if expectedVars := []interface{}{"tom", 18, float64(100), uint(1), "tom", 18}; !reflect.DeepEqual(stmt.Vars, expectedVars) {
+		t.Errorf("Vars expects: %v got: %v", expectedVars, stmt.Vars)
+	}
+}
+
+func TestDO_InsertColumns(t *testing.T) {
+	testDB, stmt := captureDB()
+
+	var do DO
+	do.UseDB(testDB)
+	do.UseModel(User{})
+
+	columns := []field.Expr{u.Name, u.Age, u.Score}
+	values := [][]interface{}{{"tom", 18, 90.5}, {"jerry", 20, 85.0}}
+	expected := "INSERT INTO `users_info` (`name`,`age`,`score`) VALUES (?,?,?),(?,?,?)"
+	for i := 0; i < 3; i++ {
+		if err := do.InsertColumns(columns, values); err != nil {
+			t.Fatalf("insert fail: %s", err)
+		}
+		if sql := stmt.SQL; sql != expected {
+			t.Errorf("SQL expects: %s\ngot: %s", expected, sql)
+		}
+		if expectedVars := []interface{}{"tom", 18, 90.5, "jerry", 20, 85.0}; !reflect.DeepEqual(stmt.Vars, expectedVars) {
+			t.Errorf("Vars expects: %v got: %v", expectedVars, stmt.Vars)
+		}
+	}
+
+	if err := do.InsertColumns(columns, [][]interface{}{{"tom", 18}}); err == nil {
+		t.Errorf("expect error for row not matching columns")
+	}
+}
+
+// sqlStateError mock driver error with SQLSTATE
+type sqlStateError string
+
+func (e sqlStateError) Error() string { return "mock error " + string(e) }
+
+func (e sqlStateError) SQLState() string { return string(e) }
+
+func failingWriteDO(err error) *DO {
+	testDB, _ := gorm.Open(mysqlDialectors{}, &gorm.Config{DryRun: true, SkipDefaultTransaction: true})
+	fail := func(tx *gorm.DB) { _ = tx.AddError(err) }
+	_ = testDB.Callback().Create().Before("gorm:create").Register("test:fail", fail)
+	_ = testDB.Callback().Update().Before("gorm:update").Register("test:fail", fail)
+	_ = testDB.Callback().Delete().Before("gorm:delete").Register("test:fail", fail)
+
+	var do DO
+	do.UseDB(testDB)
+	do.UseModel(User{})
+	return &do
+}
+
+func TestDO_Create_exclusionViolation(t *testing.T) {
+	err := failingWriteDO(sqlStateError("23P01")).Create(&User{Name: "tom"})
+	if !errors.Is(err, ErrExclusionViolation) {
+		t.Errorf("expect ErrExclusionViolation, got %v", err)
+	}
+	var stateErr sqlStateError
+	if !errors.As(err, &stateErr) || stateErr != "23P01" {
+		t.Errorf("expect driver error kept, got %v", err)
+	}
+
+	if err := failingWriteDO(sqlStateError("42601")).Save(&User{Name: "tom"}); errors.Is(err, ErrExclusionViolation) {
+		t.Errorf("expect other SQLSTATE not mapped, got %v", err)
+	}
+}
+
+func TestDO_write_constraintViolation(t *testing.T) {
+	testcases := []struct {
+		SQLState string
+		Expected error
+	}{
+		{SQLState: "23505", Expected: ErrDuplicate},
+		{SQLState: "23503", Expected: ErrForeignKey},
+		{SQLState: "23502", Expected: ErrNotNull},
+		{SQLState: "23514", Expected: ErrCheck},
+	}
+
+	for _, testcase := range testcases {
+		mockErr := sqlStateError(testcase.SQLState)
+		errs := map[string]error{
+			"Create": failingWriteDO(mockErr).Create(&User{Name: "tom"}),
+			"Save":   failingWriteDO(mockErr).Save(&User{Name: "tom"}),
+		}
+		_, errs["Update"] = failingWriteDO(mockErr).Where(field.NewInt("", "id").Eq(1)).Update(field.NewString("", "name"), "tom")
+		_, errs["Delete"] = failingWriteDO(mockErr).Where(field.NewInt("", "id").Eq(1)).Delete()
+
+		for method, err := range errs {
+			if !errors.Is(err, testcase.Expected) {
+				t.Errorf("%s with SQLSTATE %s expect %v, got %v", method, testcase.SQLState, testcase.Expected, err)
+			}
+			var stateErr sqlStateError
+			if !errors.As(err, &stateErr) || stateErr != mockErr {
+				t.Errorf("%s expect driver error kept, got %v", method, err)
+			}
+		}
+	}
+}
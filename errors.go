@@ -5,4 +5,73 @@ import "errors"
 var (
 	// ErrEmptyCondition empty condition
 	ErrEmptyCondition = errors.New("empty condition")
+
+	// ErrTooManyBindVars a single row needs more bind vars than the limit of a statement
+	ErrTooManyBindVars = errors.New("too many bind vars in a single statement")
+
+	// ErrNegativeLimit negative limit or offset
+	ErrNegativeLimit = errors.New("limit and offset must not be negative")
+
+	// ErrUnsupportedDialect the clause is not supported by the dialect of db
+	ErrUnsupportedDialect = errors.New("unsupported by dialect")
+
+	// ErrCartesianJoin join without ON and USING conditions in strict join mode
+	ErrCartesianJoin = errors.New("join without ON or USING produces a cartesian product")
+
+	// ErrAliasShadowsColumn an output alias has the name of a column of the table
+	ErrAliasShadowsColumn = errors.New("alias shadows a column")
+
+	// ErrUndeterminableColumns output columns of a subquery can not be determined from its SELECT
+	ErrUndeterminableColumns = errors.New("projected columns are undeterminable")
+
+	// ErrDuplicateAssignment a column is assigned more than once in an update
+	ErrDuplicateAssignment = errors.New("column assigned more than once")
+
+	// ErrExclusionViolation exclusion constraint violation, SQLSTATE 23P01, e.g. overlapping bookings
+	ErrExclusionViolation = errors.New("exclusion constraint violation")
+
+	// ErrDuplicate unique constraint violation, SQLSTATE 23505
+	ErrDuplicate = errors.New("unique constraint violation")
+
+	// ErrForeignKey foreign key constraint violation, SQLSTATE 23503
+	ErrForeignKey = errors.New("foreign key constraint violation")
+
+	// ErrNotNull not null constraint violation, SQLSTATE 23502
+	ErrNotNull = errors.New("not null constraint violation")
+
+	// ErrCheck check constraint violation, SQLSTATE 23514
+	ErrCheck = errors.New("check constraint violation")
 )
+
+// sqlStateErrors sentinel errors of constraint violation SQLSTATEs
+var sqlStateErrors = map[string]error{
+	"23502": ErrNotNull,
+	"23503": ErrForeignKey,
+	"23505": ErrDuplicate,
+	"23514": ErrCheck,
+	"23P01": ErrExclusionViolation,
+}
+
+// constraintError driver error of a constraint violation, it matches both the driver error and its sentinel error
+type constraintError struct {
+	sentinel error
+	err      error
+}
+
+func (e *constraintError) Error() string { return e.sentinel.Error() + ": " + e.err.Error() }
+
+func (e *constraintError) Unwrap() error { return e.err }
+
+func (e *constraintError) Is(target error) bool { return target == e.sentinel }
+
+// translateError map driver error with a constraint violation SQLSTATE to its sentinel error
+func translateError(err error) error {
+	var stateErr interface{ SQLState() string }
+	if err == nil || !errors.As(err, &stateErr) {
+		return err
+	}
+	if sentinel, ok := sqlStateErrors[stateErr.SQLState()]; ok {
+		return &constraintError{sentinel: sentinel, err: err}
+	}
+	return err
+}
@@ -0,0 +1,64 @@
+package gen
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// TestingT is the subset of testing.TB used by test helpers
+type TestingT interface {
+	Helper()
+	Errorf(format string, args ...interface{})
+}
+
+// ExplainPlan run EXPLAIN of query d on db and return the plan as text,
+// a line per plan row with values of the row in column name order, db defaults to the db of d
+func ExplainPlan(db *gorm.DB, d Dao) (string, error) {
+	stmt := d.underlyingDB().Session(&gorm.Session{DryRun: true}).Find(&[]map[string]interface{}{})
+	if stmt.Error != nil {
+		return "", stmt.Error
+	}
+	if db == nil {
+		db = d.underlyingDB()
+	}
+
+	var rows []map[string]interface{}
+	if err := db.Session(&gorm.Session{NewDB: true}).Raw("EXPLAIN "+stmt.Statement.SQL.String(), stmt.Statement.Vars...).Find(&rows).Error; err != nil {
+		return "", err
+	}
+
+	lines := make([]string, 0, len(rows))
+	for _, row := range rows {
+		columns := make([]string, 0, len(row))
+		for column := range row {
+			columns = append(columns, column)
+		}
+		sort.Strings(columns)
+
+		values := make([]string, 0, len(columns))
+		for _, column := range columns {
+			if value := row[column]; value != nil {
+				values = append(values, fmt.Sprint(value))
+			}
+		}
+		lines = append(lines, strings.Join(values, "\t"))
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// AssertUsesIndex fail t unless the plan of query d on db mentions index indexName
+func AssertUsesIndex(t TestingT, db *gorm.DB, d Dao, indexName string) {
+	t.Helper()
+
+	plan, err := ExplainPlan(db, d)
+	if err != nil {
+		t.Errorf("explain query fail: %s", err)
+		return
+	}
+	if !strings.Contains(plan, indexName) {
+		t.Errorf("query does not use index %s, plan:\n%s", indexName, plan)
+	}
+}
@@ -0,0 +1,54 @@
+package gen
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+// recordT records failures of test helpers
+type recordT struct{ errors []string }
+
+func (r *recordT) Helper() {}
+
+func (r *recordT) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+// explainDB return a db which answers any query with plan and records the executed SQL
+func explainDB(plan []map[string]interface{}) (*gorm.DB, *string) {
+	testDB, _ := gorm.Open(mysqlDialectors{}, &gorm.Config{SkipDefaultTransaction: true})
+
+	var executed string
+	_ = testDB.Callback().Query().Replace("gorm:query", func(tx *gorm.DB) {
+		executed = tx.Statement.SQL.String()
+		if dest, ok := tx.Statement.Dest.(*[]map[string]interface{}); ok {
+			*dest = plan
+		}
+	})
+	return testDB, &executed
+}
+
+func TestAssertUsesIndex(t *testing.T) {
+	db, executed := explainDB([]map[string]interface{}{
+		{"id": 1, "table": "users_info", "key": "idx_users_info_name", "rows": 1},
+	})
+	q := u.DO.Where(u.Name.Eq("tom"))
+
+	hit := new(recordT)
+	AssertUsesIndex(hit, db, q, "idx_users_info_name")
+	if len(hit.errors) != 0 {
+		t.Errorf("expect index used, got %v", hit.errors)
+	}
+	if expected := "EXPLAIN SELECT * FROM `users_info` WHERE `name` = ?"; *executed != expected {
+		t.Errorf("SQL expects: %s\ngot: %s", expected, *executed)
+	}
+
+	miss := new(recordT)
+	AssertUsesIndex(miss, db, q, "idx_users_info_age")
+	if len(miss.errors) != 1 || !strings.Contains(miss.errors[0], "idx_users_info_age") {
+		t.Errorf("expect index not used, got %v", miss.errors)
+	}
+}
@@ -0,0 +1,114 @@
+package field
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// EnumArray array of enum type field, e.g. postgres role[], values are validated against allowed values
+type EnumArray struct {
+	Field
+	allowed map[string]bool
+}
+
+// Contains array contains all values, equal to self @> values
+func (field EnumArray) Contains(values []string) Expr {
+	return field.validate(values, field.ArrayContains)
+}
+
+// ContainedBy all elements of array are in values, equal to self <@ values
+func (field EnumArray) ContainedBy(values []string) Expr {
+	return field.validate(values, field.ArrayContainedBy)
+}
+
+// Overlap array has any of values, equal to self && values
+func (field EnumArray) Overlap(values []string) Expr {
+	return field.validate(values, field.ArrayOverlap)
+}
+
+// validate build values by build if all values are allowed, or return an expression failing as condition
+func (field EnumArray) validate(values []string, build func(interface{}) Expr) Expr {
+	for _, value := range values {
+		if !field.allowed[value] {
+			return invalidExpr{expr: field.expr, err: fmt.Errorf("invalid value %q of enum array %s", value, field.col.Name)}
+		}
+	}
+	return build(StringArray(values))
+}
+
+// invalidExpr expression which fails with err when it is used as condition
+type invalidExpr struct {
+	expr
+	err error
+}
+
+func (e invalidExpr) CondError() error { return e.err }
+
+// StringArray string slice bound as a single array value, e.g. {"a","b"}
+type StringArray []string
+
+// Value implements driver.Valuer
+func (a StringArray) Value() (driver.Value, error) {
+	if a == nil {
+		return nil, nil
+	}
+	elems := make([]string, len(a))
+	for i, elem := range a {
+		elems[i] = quoteArrayElem(elem)
+	}
+	return "{" + strings.Join(elems, ",") + "}", nil
+}
+
+// sliceArray Go slice of any element type bound as a single array value, e.g. {1,2,3}
+type sliceArray struct{ values reflect.Value }
+
+// toArray wrap slice values into an array value, values which are not a slice are returned as is
+func toArray(values interface{}) interface{} {
+	switch v := values.(type) {
+	case []string:
+		return StringArray(v)
+	case driver.Valuer:
+		return v
+	}
+	if rv := reflect.ValueOf(values); rv.Kind() == reflect.Slice && rv.Type().Elem().Kind() != reflect.Uint8 {
+		return sliceArray{values: rv}
+	}
+	return values
+}
+
+// Value implements driver.Valuer
+func (a sliceArray) Value() (driver.Value, error) {
+	if a.values.IsNil() {
+		return nil, nil
+	}
+	elems := make([]string, a.values.Len())
+	for i := range elems {
+		elem := a.values.Index(i).Interface()
+		if s, ok := elem.(string); ok {
+			elems[i] = quoteArrayElem(s)
+		} else {
+			elems[i] = fmt.Sprint(elem)
+		}
+	}
+	return "{" + strings.Join(elems, ",") + "}", nil
+}
+
+// textArrayLiteral postgres text array literal written in sql, e.g. '{a,b}',
+// elements are quoted only if needed
+func textArrayLiteral(elems []string) rawSQL {
+	quoted := make([]string, len(elems))
+	for i, elem := range elems {
+		if elem == "" || strings.EqualFold(elem, "NULL") || strings.ContainsAny(elem, "{},\"\\ \t\r\n") {
+			quoted[i] = quoteArrayElem(elem)
+		} else {
+			quoted[i] = elem
+		}
+	}
+	return rawSQL("'" + strings.ReplaceAll("{"+strings.Join(quoted, ",")+"}", "'", "''") + "'")
+}
+
+func quoteArrayElem(elem string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(elem) + `"`
+}
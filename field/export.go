@@ -2,6 +2,7 @@ package field
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 
 	"gorm.io/gorm"
@@ -32,13 +33,29 @@ func NewField(table, column string, opts ...Option) Field {
 	return Field{expr: expr{col: toColumn(table, column, opts...)}}
 }
 
+// NewGenerated create new generated column field, it is read only
+func NewGenerated(table, column string, opts ...Option) Generated {
+	return Generated{NewField(table, column, opts...)}
+}
+
+// NewEnumArray create new enum array field, values of conditions must be in allowed
+func NewEnumArray(table, column string, allowed []string, opts ...Option) EnumArray {
+	set := make(map[string]bool, len(allowed))
+	for _, value := range allowed {
+		set[value] = true
+	}
+	return EnumArray{Field: NewField(table, column, opts...), allowed: set}
+}
+
 // NewUnsafeFieldRaw create new field by native sql
 //
 // Warning: Using NewUnsafeFieldRaw with raw SQL exposes your application to SQL injection vulnerabilities.
 // Always validate/sanitize inputs and prefer parameterized queries or NewField methods for field construction.
 // Use this low-level function only when absolutely necessary, and ensure any embedded values are properly escaped.
+//
+// Write "??" for a literal question mark, e.g. jsonb operator `data ?? 'key'`, so it won't be taken as a placeholder.
 func NewUnsafeFieldRaw(rawSQL string, vars ...interface{}) Field {
-	return Field{expr: expr{e: clause.Expr{SQL: rawSQL, Vars: vars}}}
+	return Field{expr: expr{e: escapeExpr(rawSQL, vars...)}}
 }
 
 // NewSerializer create new field2
@@ -55,71 +72,71 @@ func NewAsterisk(table string, opts ...Option) Asterisk {
 
 // NewInt create new Int
 func NewInt(table, column string, opts ...Option) Int {
-	return Int{expr: expr{col: toColumn(table, column, opts...)}}
+	return Int{expr: expr{col: toColumn(table, column, opts...), kind: kindNumber}}
 }
 
 // NewInt8 create new Int8
 func NewInt8(table, column string, opts ...Option) Int8 {
-	return Int8{expr: expr{col: toColumn(table, column, opts...)}}
+	return Int8{expr: expr{col: toColumn(table, column, opts...), kind: kindNumber}}
 }
 
 // NewInt16 ...
 func NewInt16(table, column string, opts ...Option) Int16 {
-	return Int16{expr: expr{col: toColumn(table, column, opts...)}}
+	return Int16{expr: expr{col: toColumn(table, column, opts...), kind: kindNumber}}
 }
 
 // NewInt32 ...
 func NewInt32(table, column string, opts ...Option) Int32 {
-	return Int32{expr: expr{col: toColumn(table, column, opts...)}}
+	return Int32{expr: expr{col: toColumn(table, column, opts...), kind: kindNumber}}
 }
 
 // NewInt64 ...
 func NewInt64(table, column string, opts ...Option) Int64 {
-	return Int64{expr: expr{col: toColumn(table, column, opts...)}}
+	return Int64{expr: expr{col: toColumn(table, column, opts...), kind: kindNumber}}
 }
 
 // NewUint ...
 func NewUint(table, column string, opts ...Option) Uint {
-	return Uint{expr: expr{col: toColumn(table, column, opts...)}}
+	return Uint{expr: expr{col: toColumn(table, column, opts...), kind: kindNumber}}
 }
 
 // NewUint8 ...
 func NewUint8(table, column string, opts ...Option) Uint8 {
-	return Uint8{expr: expr{col: toColumn(table, column, opts...)}}
+	return Uint8{expr: expr{col: toColumn(table, column, opts...), kind: kindNumber}}
 }
 
 // NewUint16 ...
 func NewUint16(table, column string, opts ...Option) Uint16 {
-	return Uint16{expr: expr{col: toColumn(table, column, opts...)}}
+	return Uint16{expr: expr{col: toColumn(table, column, opts...), kind: kindNumber}}
 }
 
 // NewUint32 ...
 func NewUint32(table, column string, opts ...Option) Uint32 {
-	return Uint32{expr: expr{col: toColumn(table, column, opts...)}}
+	return Uint32{expr: expr{col: toColumn(table, column, opts...), kind: kindNumber}}
 }
 
 // NewUint64 ...
 func NewUint64(table, column string, opts ...Option) Uint64 {
-	return Uint64{expr: expr{col: toColumn(table, column, opts...)}}
+	return Uint64{expr: expr{col: toColumn(table, column, opts...), kind: kindNumber}}
 }
 
 // ======================== float =======================
 
 // NewFloat32 ...
 func NewFloat32(table, column string, opts ...Option) Float32 {
-	return Float32{expr: expr{col: toColumn(table, column, opts...)}}
+	return Float32{expr: expr{col: toColumn(table, column, opts...), kind: kindNumber}}
 }
 
 // NewFloat64 ...
 func NewFloat64(table, column string, opts ...Option) Float64 {
-	return Float64{expr: expr{col: toColumn(table, column, opts...)}}
+	return Float64{expr: expr{col: toColumn(table, column, opts...), kind: kindNumber}}
 }
 
 // ======================== string =======================
 
 // NewString ...
 func NewString(table, column string, opts ...Option) String {
-	return String{expr: expr{col: toColumn(table, column, opts...)}}
+	return String{expr: expr{col: toColumn(table, column, opts...), kind: kindString}}
 }
 
 // NewBytes ...
@@ -131,14 +148,14 @@ func NewBytes(table, column string, opts ...Option) Bytes {
 
 // NewBool ...
 func NewBool(table, column string, opts ...Option) Bool {
-	return Bool{expr: expr{col: toColumn(table, column, opts...)}}
+	return Bool{expr: expr{col: toColumn(table, column, opts...), kind: kindBool}}
 }
 
 // ======================== time =======================
 
 // NewTime ...
 func NewTime(table, column string, opts ...Option) Time {
-	return Time{expr: expr{col: toColumn(table, column, opts...)}}
+	return Time{expr: expr{col: toColumn(table, column, opts...), kind: kindTime}}
 }
 
 func toColumn(table, column string, opts ...Option) clause.Column {
@@ -166,6 +183,65 @@ func Not(exprs ...Expr) Expr {
 	return &expr{e: clause.Not(toExpression(exprs...)...)}
 }
 
+// RowChecksum return md5 fingerprint of columns for change detection,
+// equal to md5(concat_ws('|', col1::text, col2::text, ...)), NULL columns are skipped by concat_ws
+func RowChecksum(cols ...Expr) String {
+	placeholders := make([]string, len(cols))
+	vars := make([]interface{}, len(cols))
+	for i, col := range cols {
+		placeholders[i] = "?::text"
+		vars[i] = col.RawExpr()
+	}
+	return String{expr{e: clause.Expr{SQL: "md5(concat_ws('|', " + strings.Join(placeholders, ", ") + "))", Vars: vars}}}
+}
+
+// BuildDocument return jsonb object built from pairs, keys are sorted for deterministic output,
+// equal to jsonb_build_object(key1, value1, key2, value2, ...)
+func BuildDocument(pairs map[string]Expr) Expr {
+	keys := make([]string, 0, len(pairs))
+	for key := range pairs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	placeholders := make([]string, 0, len(keys))
+	vars := make([]interface{}, 0, 2*len(keys))
+	for _, key := range keys {
+		placeholders = append(placeholders, "?, ?")
+		vars = append(vars, key, pairs[key].RawExpr())
+	}
+	return expr{e: clause.Expr{SQL: "jsonb_build_object(" + strings.Join(placeholders, ", ") + ")", Vars: vars}}
+}
+
+// Every return true if cond is true for all rows of the group, equal to every(cond)
+func Every(cond Expr) Bool {
+	return Bool{expr{e: clause.Expr{SQL: "every(?)", Vars: []interface{}{cond.RawExpr()}}}}
+}
+
+// Some return true if cond is true for any row of the group, equal to bool_or(cond)
+func Some(cond Expr) Bool {
+	return Bool{expr{e: clause.Expr{SQL: "bool_or(?)", Vars: []interface{}{cond.RawExpr()}}}}
+}
+
+// CoalesceWithSource return the first non-null value of cols and the name of the column supplying it,
+// value equal to COALESCE(col1, col2, ...), source equal to CASE WHEN col1 IS NOT NULL THEN 'col1' ... ELSE NULL END
+func CoalesceWithSource(cols []Expr) (value Expr, source String) {
+	placeholders := make([]string, len(cols))
+	values := make([]interface{}, len(cols))
+	var sql strings.Builder
+	sources := make([]interface{}, 0, 2*len(cols))
+	sql.WriteString("CASE")
+	for i, col := range cols {
+		placeholders[i] = "?"
+		values[i] = col.RawExpr()
+		sql.WriteString(" WHEN ? IS NOT NULL THEN ?")
+		sources = append(sources, col.RawExpr(), col.ColumnName().String())
+	}
+	sql.WriteString(" ELSE NULL END")
+	return expr{e: clause.Expr{SQL: "COALESCE(" + strings.Join(placeholders, ", ") + ")", Vars: values}},
+		String{expr{e: clause.Expr{SQL: sql.String(), Vars: sources}}}
+}
+
 func toExpression(conds ...Expr) []clause.Expression {
 	exprs := make([]clause.Expression, len(conds))
 	for i, cond := range conds {
@@ -305,6 +381,36 @@ func ContainsValue(columns []Expr, value Value) Expr {
 // EmptyExpr return a empty expression
 func EmptyExpr() Expr { return expr{e: clause.Expr{}} }
 
+// Default return DEFAULT keyword to assign the default value of column explicitly, e.g. col.SetCol(field.Default())
+func Default() AssignExpr { return expr{e: clause.Expr{SQL: "DEFAULT"}} }
+
+// ChangeGuard assignment guarded by col IS DISTINCT FROM value, see SetIfChanged
+type ChangeGuard interface {
+	AssignExpr
+
+	Guard() Expr
+}
+
+type changeGuard struct {
+	expr
+	guard Expr
+}
+
+func (c changeGuard) Guard() Expr { return c.guard }
+
+// SetIfChanged assign value to col, the assignment carries guard col IS DISTINCT FROM value,
+// add it to WHERE by DO.OnlyIfChanged to skip no-op updates, assigning a Generated column fails by CondError
+func SetIfChanged(col Expr, value interface{}) AssignExpr {
+	if generated, ok := col.(Generated); ok {
+		return generated.invalidAssign()
+	}
+	name, value := string(col.ColumnName()), toRawValue(value)
+	return changeGuard{
+		expr:  expr{col: clause.Column{Name: name}, e: clause.Eq{Column: name, Value: value}},
+		guard: expr{e: clause.Expr{SQL: "? IS DISTINCT FROM ?", Vars: []interface{}{col.RawExpr(), value}}},
+	}
+}
+
 // AssociationFields all association
 var AssociationFields Expr = NewString("", clause.Associations).appendBuildOpts(WithoutQuote)
 
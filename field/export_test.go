@@ -1,15 +1,25 @@
 package field_test
 
 import (
+	"database/sql"
 	"database/sql/driver"
 	"fmt"
+	"reflect"
 	"strings"
 	"testing"
 	"time"
 
+	"gorm.io/gorm"
+	"gorm.io/gorm/utils/tests"
+
 	"gorm.io/gen/field"
 )
 
+// columnValuer custom column type which is both an Expr and a driver.Valuer
+type columnValuer struct{ field.Expr }
+
+func (columnValuer) Value() (driver.Value, error) { return nil, nil }
+
 var _ field.ScanValuer = new(password)
 
 type password string
@@ -41,6 +51,18 @@ func TestExpr_Build(t *testing.T) {
 			Expr:   field.NewField("", "id").EqCol(field.NewField("", "new_id")),
 			Result: "`id` = `new_id`",
 		},
+		{
+			Expr:   field.NewField("", "id").Eq(columnValuer{field.NewField("", "new_id")}),
+			Result: "`id` = `new_id`",
+		},
+		{
+			Expr:   field.NewField("", "id").Gt(columnValuer{field.NewField("t", "new_id")}),
+			Result: "`id` > `t`.`new_id`",
+		},
+		{
+			Expr:   field.NewField("", "id").Lte(columnValuer{field.NewField("", "new_id").Avg()}),
+			Result: "`id` <= AVG(`new_id`)",
+		},
 		{
 			Expr:   field.NewField("", "id").NeqCol(field.NewField("", "new_id")),
 			Result: "`id` <> `new_id`",
@@ -148,6 +170,27 @@ func TestExpr_Build(t *testing.T) {
 			Result:       "(UNIX_TIMESTAMP(?))*?",
 			ExpectedVars: []interface{}{"2005-03-27 03:00:00", uint64(99)},
 		},
+		{
+			Expr:   field.Func.NowUTC(),
+			Result: "(now() AT TIME ZONE 'UTC')",
+		},
+		{
+			Expr:   field.Func.StatementTimestamp(),
+			Result: "statement_timestamp()",
+		},
+		{
+			Expr:   field.Func.ClockTimestamp(),
+			Result: "clock_timestamp()",
+		},
+		{
+			Expr:         field.Func.ConversionRate(field.NewInt("", "step").Eq(2), field.NewInt("", "step").Eq(1)),
+			ExpectedVars: []interface{}{2, 1},
+			Result:       "COUNT(*) FILTER (WHERE `step` = ?)::float / NULLIF(COUNT(*) FILTER (WHERE `step` = ?), 0)",
+		},
+		{
+			Expr:   field.NewTime("", "updated_at").LtCol(field.Func.NowUTC()),
+			Result: "`updated_at` < (now() AT TIME ZONE 'UTC')",
+		},
 		{
 			Expr:   field.NewInt("t1", "id").AddCol(field.NewInt("t2", "num")),
 			Result: "`t1`.`id` + `t2`.`num`",
@@ -158,7 +201,41 @@ func TestExpr_Build(t *testing.T) {
 		},
 		{
 			Expr:   field.NewInt("t1", "id").AddCol(field.NewInt("t1", "num")).SubCol(field.NewInt("t1", "age")).MulCol(field.NewInt("t1", "age")).DivCol(field.NewInt("t1", "base")),
-			Result: "((`t1`.`id` + `t1`.`num` - `t1`.`age`) * (`t1`.`age`)) / (`t1`.`base`)",
+			Result: "(`t1`.`id` + `t1`.`num` - `t1`.`age`) * `t1`.`age` / `t1`.`base`",
+		},
+		{
+			Expr:   field.NewInt("", "a").AddCol(field.NewInt("", "b").MulCol(field.NewInt("", "c"))),
+			Result: "`a` + `b` * `c`",
+		},
+		{
+			Expr:   field.NewInt("", "a").AddCol(field.NewInt("", "b")).MulCol(field.NewInt("", "c")),
+			Result: "(`a` + `b`) * `c`",
+		},
+		{
+			Expr:   field.NewInt("", "a").AddCol(field.NewInt("", "b")).(field.Field).Parenthesize().MulCol(field.NewInt("", "c")),
+			Result: "(`a` + `b`) * `c`",
+		},
+		{
+			Expr:   field.NewInt("", "a").DivCol(field.NewInt("", "b").SubCol(field.NewInt("", "c"))),
+			Result: "`a` / (`b` - `c`)",
+		},
+		{
+			Expr:   field.NewInt("", "a").SubCol(field.NewInt("", "b").AddCol(field.NewInt("", "c")).(field.Field).Parenthesize()),
+			Result: "`a` - (`b` + `c`)",
+		},
+		{
+			Expr:   field.NewInt("", "a").SubCol(field.NewInt("", "b").AddCol(field.NewInt("", "c"))),
+			Result: "`a` - (`b` + `c`)",
+		},
+		{
+			Expr:         field.NewInt("", "a").Add(1).MulCol(field.NewInt("", "b").Mul(2)),
+			ExpectedVars: []interface{}{1, 2},
+			Result:       "(`a`+?) * (`b`*?)",
+		},
+		{
+			Expr:         field.NewInt("", "a").Mul(2).DivCol(field.NewInt("", "b")).SubCol(field.NewInt("", "c").Sub(1)),
+			ExpectedVars: []interface{}{2, 1},
+			Result:       "`a`*? / `b` - (`c`-?)",
 		},
 		{
 			Expr:         field.NewInt("t1", "id").AddCol(field.NewInt("t2", "num").Add(1)),
@@ -201,6 +278,14 @@ func TestExpr_Build(t *testing.T) {
 			Expr:   field.NewUint("", "i`d"),
 			Result: "`i``d`",
 		},
+		{
+			Expr:   field.NewString("", "category").ArgMax(field.NewFloat64("", "total")),
+			Result: "(array_agg(`category` ORDER BY `total` DESC))[1]",
+		},
+		{
+			Expr:   field.NewString("", "category").ArgMax(field.NewFloat64("", "amount").Sum()),
+			Result: "(array_agg(`category` ORDER BY SUM(`amount`) DESC))[1]",
+		},
 		{
 			Expr:   field.NewUint("", "id").Avg(),
 			Result: "AVG(`id`)",
@@ -384,6 +469,267 @@ func TestExpr_Build(t *testing.T) {
 			ExpectedVars: []interface{}{"address", "path"},
 			Result:       "REPLACE(`address`,?,?)",
 		},
+		{
+			Expr:   field.NewString("", "payload").MD5(),
+			Result: "md5(`payload`)",
+		},
+		{
+			Expr:   field.NewString("t", "payload").SHA256Hex(),
+			Result: "encode(sha256(`t`.`payload`::bytea), 'hex')",
+		},
+		{
+			Expr:   field.RowChecksum(field.NewInt("", "id"), field.NewString("", "name"), field.NewTime("", "updated_at")),
+			Result: "md5(concat_ws('|', `id`::text, `name`::text, `updated_at`::text))",
+		},
+		{
+			Expr: field.BuildDocument(map[string]field.Expr{
+				"name":  field.NewString("", "name"),
+				"id":    field.NewInt("", "id"),
+				"owner": field.BuildDocument(map[string]field.Expr{"name": field.NewString("owner", "name")}),
+			}),
+			ExpectedVars: []interface{}{"id", "name", "owner", "name"},
+			Result:       "jsonb_build_object(?, `id`, ?, `name`, ?, jsonb_build_object(?, `owner`.`name`))",
+		},
+		{
+			Expr: field.NewField("", "grade").CaseWhen(
+				[]field.Expr{field.NewInt("", "score").Gte(90), field.NewInt("", "score").Gte(60)},
+				[]field.Expr{field.NewString("", "grade_a"), field.NewString("", "grade_b")},
+			),
+			ExpectedVars: []interface{}{90, 60},
+			Result:       "CASE WHEN `score` >= ? THEN `grade_a` WHEN `score` >= ? THEN `grade_b` ELSE NULL END",
+		},
+		{
+			Expr: field.NewField("", "grade").CaseWhen(
+				[]field.Expr{field.NewInt("", "score").Gte(60)},
+				[]field.Expr{field.NewString("", "grade_pass")},
+				"fail",
+			),
+			ExpectedVars: []interface{}{60, "fail"},
+			Result:       "CASE WHEN `score` >= ? THEN `grade_pass` ELSE ? END",
+		},
+		{
+			Expr:   field.NewField("", "tags").CoalesceArray(field.NewField("", "default_tags")),
+			Result: "COALESCE(NULLIF(`tags`, '{}'), `default_tags`)",
+		},
+		{
+			Expr:         field.NewField("", "tags").CoalesceArray([]string{"general"}),
+			ExpectedVars: []interface{}{field.StringArray{"general"}},
+			Result:       "COALESCE(NULLIF(`tags`, '{}'), ?)",
+		},
+		{
+			Expr:         field.NewInt("", "score").Coalesce(0),
+			ExpectedVars: []interface{}{0},
+			Result:       "COALESCE(`score`,?)",
+		},
+		{
+			Expr:   field.NewInt("", "score").Coalesce(field.NewInt("", "default_score")),
+			Result: "COALESCE(`score`,`default_score`)",
+		},
+		{
+			Expr:         field.NewInt("", "score").Coalesce(field.NewInt("", "default_score"), 0),
+			ExpectedVars: []interface{}{0},
+			Result:       "COALESCE(`score`,`default_score`,?)",
+		},
+		{
+			Expr:         field.NewField("", "phone").Replace("-", "").As("digits"),
+			ExpectedVars: []interface{}{"-", ""},
+			Result:       "REPLACE(`phone`,?,?) AS `digits`",
+		},
+		{
+			Expr:         field.NewString("", "sku").Replace("-", "").Lower(),
+			ExpectedVars: []interface{}{"-", ""},
+			Result:       "LOWER(REPLACE(`sku`,?,?))",
+		},
+		{
+			Expr:         field.NewField("", "code").Substring(2, 3).As("short_code"),
+			ExpectedVars: []interface{}{2, 3},
+			Result:       "SUBSTRING(`code` FROM ? FOR ?) AS `short_code`",
+		},
+		{
+			Expr:         field.NewField("", "code").Substring(2),
+			ExpectedVars: []interface{}{2},
+			Result:       "SUBSTRING(`code` FROM ?)",
+		},
+		{
+			Expr:         field.Every(field.NewInt("", "score").Gte(60)),
+			ExpectedVars: []interface{}{60},
+			Result:       "every(`score` >= ?)",
+		},
+		{
+			Expr:         field.Some(field.NewString("", "status").Eq("failed")),
+			ExpectedVars: []interface{}{"failed"},
+			Result:       "bool_or(`status` = ?)",
+		},
+		{
+			Expr:   field.NewInt("", "age").SetCol(field.Default()),
+			Result: "`age` = DEFAULT",
+		},
+		{
+			Expr:         field.NewField("", "attrs").JsonbSet([]string{"profile", "name"}, `"tom"`),
+			ExpectedVars: []interface{}{`"tom"`},
+			Result:       "`attrs` = jsonb_set(`attrs`, '{profile,name}', ?)",
+		},
+		{
+			Expr:   field.NewField("", "attrs").JsonbSet([]string{"it's", "a b"}, field.NewField("", "profile")),
+			Result: "`attrs` = jsonb_set(`attrs`, '{it''s,\"a b\"}', `profile`)",
+		},
+		{
+			Expr:         field.NewField("", "attrs").JsonbInsert([]string{"tags", "0"}, `"new"`),
+			ExpectedVars: []interface{}{`"new"`},
+			Result:       "`attrs` = jsonb_insert(`attrs`, '{tags,0}', ?)",
+		},
+		{
+			Expr:         field.NewField("", "attrs").JsonbShallowMerge(`{"a":{"b":1}}`),
+			ExpectedVars: []interface{}{`{"a":{"b":1}}`},
+			Result:       "`attrs` || ?",
+		},
+		{
+			Expr:   field.NewField("", "attrs").JsonbShallowMerge(field.NewField("", "patch")),
+			Result: "`attrs` || `patch`",
+		},
+		{
+			Expr:         field.NewField("", "attrs").JsonbDeepMerge(`{"a":{"b":1}}`, "jsonb_deep_merge"),
+			ExpectedVars: []interface{}{`{"a":{"b":1}}`},
+			Result:       "jsonb_deep_merge(`attrs`, ?)",
+		},
+		{
+			Expr:         field.NewString("", "name").StringAgg(", "),
+			ExpectedVars: []interface{}{", "},
+			Result:       "STRING_AGG(`name`, ?)",
+		},
+		{
+			Expr:         field.NewString("", "name").StringAggOrdered(", ", field.NewString("", "name")),
+			ExpectedVars: []interface{}{", "},
+			Result:       "STRING_AGG(`name`, ? ORDER BY `name`)",
+		},
+		{
+			Expr:         field.NewString("", "name").StringAggOrdered(";", field.NewInt("", "age").Desc(), field.NewInt("", "id").Add(1)),
+			ExpectedVars: []interface{}{";", 1},
+			Result:       "STRING_AGG(`name`, ? ORDER BY `age` DESC,`id`+?)",
+		},
+		{
+			Expr:   field.NewField("", "tags").Unnest().As("tag"),
+			Result: "UNNEST(`tags`) AS `tag`",
+		},
+		{
+			Expr:   field.NewField("", "tags").UnnestWithOrdinality(),
+			Result: "UNNEST(`tags`) WITH ORDINALITY",
+		},
+		{
+			Expr:         field.NewField("", "tags").ArrayLength(1),
+			ExpectedVars: []interface{}{1},
+			Result:       "array_length(`tags`, ?)",
+		},
+		{
+			Expr:         field.NewField("", "tags").ArrayLength(1).Add(1).Gt(3),
+			ExpectedVars: []interface{}{1, 1, 3},
+			Result:       "array_length(`tags`, ?)+? > ?",
+		},
+		{
+			Expr:         field.NewField("", "tags").ArrayPosition("go"),
+			ExpectedVars: []interface{}{"go"},
+			Result:       "array_position(`tags`, ?)",
+		},
+		{
+			Expr:         field.NewField("", "tags").ArrayRemove("go"),
+			ExpectedVars: []interface{}{"go"},
+			Result:       "array_remove(`tags`, ?)",
+		},
+		{
+			Expr:   field.NewField("", "tags").ArrayRemove(field.NewString("", "tag")),
+			Result: "array_remove(`tags`, `tag`)",
+		},
+		{
+			Expr:         field.NewFloat64("", "price").Round(2),
+			ExpectedVars: []interface{}{2},
+			Result:       "ROUND(`price`, ?)",
+		},
+		{
+			Expr:         field.NewFloat64("", "price").Mul(1.1).Round(2).Add(1),
+			ExpectedVars: []interface{}{1.1, 2, 1.0},
+			Result:       "ROUND(`price`*?, ?)+?",
+		},
+		{
+			Expr:   field.NewFloat64("", "price").Ceil(),
+			Result: "CEIL(`price`)",
+		},
+		{
+			Expr:         field.NewInt("", "total").Div(3).FloorF().Mul(2),
+			ExpectedVars: []interface{}{3, 2.0},
+			Result:       "(FLOOR(`total`/?))*?",
+		},
+		{
+			Expr:         field.NewFloat64("", "price").Trunc(0),
+			ExpectedVars: []interface{}{0},
+			Result:       "TRUNC(`price`, ?)",
+		},
+		{
+			Expr:         field.NewFloat64("", "rate").Power(2),
+			ExpectedVars: []interface{}{2},
+			Result:       "POWER(`rate`, ?)",
+		},
+		{
+			Expr:   field.NewFloat64("", "rate").Power(field.NewInt("", "years")),
+			Result: "POWER(`rate`, `years`)",
+		},
+		{
+			Expr:   field.NewFloat64("", "variance").Avg().Sqrt(),
+			Result: "SQRT(AVG(`variance`))",
+		},
+		{
+			Expr:   field.NewFloat64("", "growth").Abs().Ln(),
+			Result: "LN(ABS(`growth`))",
+		},
+		{
+			Expr:         field.NewFloat64("", "amount").Log(10),
+			ExpectedVars: []interface{}{10},
+			Result:       "LOG(?, `amount`)",
+		},
+		{
+			Expr:         field.NewFloat64("", "amount").Log(2).Gt(8),
+			ExpectedVars: []interface{}{2, 8.0},
+			Result:       "LOG(?, `amount`) > ?",
+		},
+		{
+			Expr:   field.NewFloat64("", "rate").Exp(),
+			Result: "EXP(`rate`)",
+		},
+		{
+			Expr:         field.NewInt("", "score").Greatest(field.NewInt("", "bonus"), 60, field.NewInt("", "bonus").Add(10)),
+			ExpectedVars: []interface{}{60, 10},
+			Result:       "GREATEST(`score`,`bonus`,?,`bonus`+?)",
+		},
+		{
+			Expr:         field.NewFloat64("", "price").Least(9.9, field.NewFloat64("", "discount_price")),
+			ExpectedVars: []interface{}{9.9},
+			Result:       "LEAST(`price`,?,`discount_price`)",
+		},
+		{
+			Expr:   field.NewString("", "tag").ArrayAgg(),
+			Result: "ARRAY_AGG(`tag`)",
+		},
+		{
+			Expr:   field.NewString("", "tag").ArrayAggDistinct().As("tags"),
+			Result: "ARRAY_AGG(DISTINCT `tag`) AS `tags`",
+		},
+		{
+			Expr:   field.NewString("", "tag").ArrayAggOrdered(field.NewInt("", "priority").Desc(), field.NewString("", "tag")),
+			Result: "ARRAY_AGG(`tag` ORDER BY `priority` DESC,`tag`)",
+		},
+		{
+			Expr:   field.NewString("", "nickname").EmptyToNull(),
+			Result: "NULLIF(`nickname`, '')",
+		},
+		{
+			Expr:         field.NewString("", "nickname").EmptyToNull().Coalesce("default"),
+			ExpectedVars: []interface{}{"default"},
+			Result:       "COALESCE(NULLIF(`nickname`, ''),?)",
+		},
+		{
+			Expr:         field.NewString("", "nickname").EmptyToNull().Coalesce(field.NewString("", "name"), "default"),
+			ExpectedVars: []interface{}{"default"},
+			Result:       "COALESCE(NULLIF(`nickname`, ''),`name`,?)",
+		},
 		{
 			Expr:         field.NewString("", "address").Concat("[", "]"),
 			ExpectedVars: []interface{}{"[", "]"},
@@ -399,6 +745,76 @@ func TestExpr_Build(t *testing.T) {
 			ExpectedVars: []interface{}{"[", "address", "path", "]"},
 			Result:       "CONCAT(?,REPLACE(`address`,?,?),?)",
 		},
+		// ======================== array ========================
+		{
+			Expr:         field.NewString("", "tag").EqAny("{go,sql}"),
+			ExpectedVars: []interface{}{"{go,sql}"},
+			Result:       "`tag` = ANY(?)",
+		},
+		{
+			Expr:   field.NewString("", "tag").EqAny(field.NewField("", "tags")),
+			Result: "`tag` = ANY(`tags`)",
+		},
+		{
+			Expr:         field.NewString("", "tag").NotEqAny("{go,sql}"),
+			ExpectedVars: []interface{}{"{go,sql}"},
+			Result:       "NOT (`tag` = ANY(?))",
+		},
+		{
+			Expr:   field.NewString("", "tag").NotEqAny(field.NewField("", "tags")),
+			Result: "NOT (`tag` = ANY(`tags`))",
+		},
+		{
+			Expr:         field.NewString("", "tag").Lower().NotEqAny("{go}"),
+			ExpectedVars: []interface{}{"{go}"},
+			Result:       "NOT (LOWER(`tag`) = ANY(?))",
+		},
+		{
+			Expr:         field.NewString("", "tag").NeqAll("{go,sql}"),
+			ExpectedVars: []interface{}{"{go,sql}"},
+			Result:       "`tag` <> ALL(?)",
+		},
+		// ======================== jsonb ========================
+		{
+			Expr:         field.NewField("", "attrs").JsonbHasKey("role"),
+			ExpectedVars: []interface{}{"role"},
+			Result:       "`attrs` ? ?",
+		},
+		{
+			Expr:         field.And(field.NewField("", "attrs").JsonbHasKey("role"), field.NewField("", "password").Eq(p)),
+			ExpectedVars: []interface{}{"role", p},
+			Result:       "(`attrs` ? ? AND `password` = ?)",
+		},
+		{
+			Expr:         field.NewField("", "attrs").JsonbHasAnyKey([]string{"role", "name"}),
+			ExpectedVars: []interface{}{"role", "name"},
+			Result:       "`attrs` ?| ARRAY[?,?]",
+		},
+		{
+			Expr:         field.NewField("", "attrs").JsonbHasAllKeys([]string{"role", "name"}),
+			ExpectedVars: []interface{}{"role", "name"},
+			Result:       "`attrs` ?& ARRAY[?,?]",
+		},
+		{
+			Expr:         field.NewField("", "attrs").JsonPathExists(`$.tags[*] ? (@ == "vip")`),
+			ExpectedVars: []interface{}{`$.tags[*] ? (@ == "vip")`},
+			Result:       "`attrs` @? ?::jsonpath",
+		},
+		{
+			Expr:         field.And(field.NewField("", "attrs").JsonPathMatch("$.age > 18"), field.NewField("", "password").Eq(p)),
+			ExpectedVars: []interface{}{"$.age > 18", p},
+			Result:       "(`attrs` @@ ?::jsonpath AND `password` = ?)",
+		},
+		{
+			Expr:         field.NewUnsafeFieldRaw("`attrs` ?? ?", "role"),
+			ExpectedVars: []interface{}{"role"},
+			Result:       "`attrs` ? ?",
+		},
+		{
+			Expr:         field.NewUnsafeFieldRaw("to_tsvector(?) @@ ? AND `attrs` ??| ?", "text", "query", "{role,name}").IsNull(),
+			ExpectedVars: []interface{}{"text", "query", "{role,name}"},
+			Result:       "to_tsvector(?) @@ ? AND `attrs` ?| ? IS NULL",
+		},
 		// ======================== time ========================
 		{
 			Expr:         field.NewTime("", "creatAt").Eq(timeData),
@@ -440,6 +856,74 @@ func TestExpr_Build(t *testing.T) {
 			ExpectedVars: []interface{}{time.Duration(24 * time.Hour).Microseconds()},
 			Result:       "DATE_SUB(`creatAt`, INTERVAL ? MICROSECOND)",
 		},
+		{
+			Expr:         field.RangeOverlaps(field.NewTime("", "starts_at"), field.NewTime("", "ends_at"), timeData, timeData.Add(time.Hour)),
+			ExpectedVars: []interface{}{timeData, timeData.Add(time.Hour)},
+			Result:       "tstzrange(`starts_at`, `ends_at`) && tstzrange(?, ?)",
+		},
+		{
+			Expr:         field.RangeOverlaps(field.NewTime("", "starts_at"), field.NewTime("", "ends_at"), timeData, timeData.Add(time.Hour), "[]"),
+			ExpectedVars: []interface{}{"[]", timeData, timeData.Add(time.Hour), "[]"},
+			Result:       "tstzrange(`starts_at`, `ends_at`, ?) && tstzrange(?, ?, ?)",
+		},
+		{
+			Expr:         field.NewTime("", "createdAt").DateTrunc("day"),
+			ExpectedVars: []interface{}{"day"},
+			Result:       "DATE_TRUNC(?,`createdAt`)",
+		},
+		{
+			Expr:         field.NewField("", "created_at").DateTrunc("month"),
+			ExpectedVars: []interface{}{"month"},
+			Result:       "DATE_TRUNC(?,`created_at`)",
+		},
+		{
+			Expr:         field.NewTime("", "created_at").DateTrunc("month").Gte(timeData),
+			ExpectedVars: []interface{}{"month", timeData},
+			Result:       "DATE_TRUNC(?,`created_at`) >= ?",
+		},
+		{
+			Expr:   field.NewTime("", "created_at").Extract("year"),
+			Result: "EXTRACT(year FROM `created_at`)",
+		},
+		{
+			Expr:         field.NewTime("", "created_at").Extract("YEAR").Gt(2020),
+			ExpectedVars: []interface{}{2020.0},
+			Result:       "EXTRACT(YEAR FROM `created_at`) > ?",
+		},
+		{
+			Expr:         field.NewTime("", "created_at").AddInterval("1 day"),
+			ExpectedVars: []interface{}{"1 day"},
+			Result:       "`created_at` + ?::interval",
+		},
+		{
+			Expr:         field.NewTime("", "created_at").SubInterval("2 hours").Lt(timeData),
+			ExpectedVars: []interface{}{"2 hours", timeData},
+			Result:       "`created_at` - ?::interval < ?",
+		},
+		{
+			Expr:         field.NewTime("", "created_at").DateTrunc("month").AddInterval("1 month").SubInterval("1 day"),
+			ExpectedVars: []interface{}{"month", "1 month", "1 day"},
+			Result:       "DATE_TRUNC(?,`created_at`) + ?::interval - ?::interval",
+		},
+		{
+			Expr:         field.NewTime("", "created_at").Add(time.Hour),
+			ExpectedVars: []interface{}{int64(3600000000)},
+			Result:       "DATE_ADD(`created_at`, INTERVAL ? MICROSECOND)",
+		},
+		{
+			Expr:   field.NewTime("", "ended_at").AgeBetween(field.NewTime("", "started_at")),
+			Result: "AGE(`ended_at`, `started_at`)",
+		},
+		{
+			Expr:         field.NewTime("", "ended_at").AgeBetween(field.NewTime("", "started_at").DateTrunc("day")).Extract("day"),
+			ExpectedVars: []interface{}{"day"},
+			Result:       "EXTRACT(day FROM AGE(`ended_at`, DATE_TRUNC(?,`started_at`)))",
+		},
+		{
+			Expr:         field.NewTime("", "ended_at").AgeBetween(field.NewTime("", "started_at")).DatePart("year"),
+			ExpectedVars: []interface{}{"year"},
+			Result:       "DATE_PART(?, AGE(`ended_at`, `started_at`))",
+		},
 		{
 			Expr:         field.NewTime("", "updateAt").DateFormat("%W %M %Y"),
 			ExpectedVars: []interface{}{"%W %M %Y"},
@@ -626,3 +1110,283 @@ func expectedStruct() { // nolint
 		},
 	}
 }
+
+func TestFunc_ApproxCountDistinct(t *testing.T) {
+	userID := field.NewInt("", "user_id")
+	field.CheckBuildExpr(t, field.Func.ApproxCountDistinct(userID), "COUNT(DISTINCT `user_id`)", nil)
+
+	field.RegisterApproxCountDistinct("dummy", "hll_cardinality(hll_add_agg(hll_hash_any(?)))")
+	defer field.RegisterApproxCountDistinct("dummy", "")
+	field.CheckBuildExpr(t, field.Func.ApproxCountDistinct(userID), "hll_cardinality(hll_add_agg(hll_hash_any(`user_id`)))", nil)
+
+	field.RegisterApproxCountDistinct("dummy", "")
+	field.CheckBuildExpr(t, field.Func.ApproxCountDistinct(userID), "COUNT(DISTINCT `user_id`)", nil)
+}
+
+func TestGenerated(t *testing.T) {
+	total := field.NewGenerated("", "total")
+	hundred := sql.NullInt64{Int64: 100, Valid: true}
+	field.CheckBuildExpr(t, total.Gt(hundred), "`total` > ?", []interface{}{hundred})
+	field.CheckBuildExpr(t, total.Desc(), "`total` DESC", nil)
+
+	assignments := map[string]field.AssignExpr{
+		"Value":        total.Value(hundred),
+		"Null":         total.Null(),
+		"SetCol":       total.SetCol(field.NewInt("", "price")),
+		"SetIfChanged": field.SetIfChanged(total, 1),
+		"JsonbSet":     total.JsonbSet([]string{"a"}, 1),
+		"JsonbInsert":  total.JsonbInsert([]string{"a"}, 1),
+	}
+	for name, assign := range assignments {
+		if err := assign.CondError(); err == nil || err.Error() != "generated column total can not be assigned" {
+			t.Errorf("%s on generated column expects error, got %v", name, err)
+		}
+	}
+}
+
+func TestEnumArray(t *testing.T) {
+	roles := field.NewEnumArray("", "roles", []string{"admin", "editor", "viewer"})
+
+	contains := roles.Contains([]string{"admin", "editor"})
+	if err := contains.CondError(); err != nil {
+		t.Fatalf("expect valid condition, got %s", err)
+	}
+	field.CheckBuildExpr(t, contains, "`roles` @> ?", []interface{}{field.StringArray{"admin", "editor"}})
+	if value, _ := (field.StringArray{"admin", `a"b`}).Value(); value != `{"admin","a\"b"}` {
+		t.Errorf("array value got %v", value)
+	}
+
+	if err := roles.Overlap([]string{"admin", "owner"}).CondError(); err == nil {
+		t.Errorf("expect error for value not in enum")
+	}
+}
+
+func TestExpr_EqAnySlice(t *testing.T) {
+	ids := make([]int64, 5000)
+	for i := range ids {
+		ids[i] = int64(i)
+	}
+
+	sql, vars := field.BuildToString(field.NewInt64("", "id").EqAnySlice(ids))
+	if sql != "`id` = ANY(?)" {
+		t.Errorf("SQL expects `id` = ANY(?) got %s", sql)
+	}
+	if len(vars) != 1 {
+		t.Fatalf("expect a single array var, got %d vars", len(vars))
+	}
+	value, err := vars[0].(driver.Valuer).Value()
+	if err != nil || !strings.HasPrefix(value.(string), "{0,1,2,") || !strings.HasSuffix(value.(string), ",4999}") {
+		t.Errorf("array value got %v, %v", value, err)
+	}
+
+	field.CheckBuildExpr(t, field.NewString("", "name").EqAnySlice([]string{"tom", "jerry"}), "`name` = ANY(?)",
+		[]interface{}{field.StringArray{"tom", "jerry"}})
+}
+
+func TestExpr_EqAnySliceChunk(t *testing.T) {
+	id := field.NewInt64("", "id")
+	sql, vars := field.BuildToString(id.EqAnySlice([]int64{1, 2, 3, 4, 5}, field.WithChunkSize(2)))
+	if sql != "(`id` = ANY(?) OR `id` = ANY(?) OR `id` = ANY(?))" {
+		t.Errorf("SQL expects three ANY groups got %s", sql)
+	}
+	var arrays []interface{}
+	for _, v := range vars {
+		value, err := v.(driver.Valuer).Value()
+		if err != nil {
+			t.Fatalf("array value fail: %s", err)
+		}
+		arrays = append(arrays, value)
+	}
+	if !reflect.DeepEqual(arrays, []interface{}{"{1,2}", "{3,4}", "{5}"}) {
+		t.Errorf("chunked arrays got %v", arrays)
+	}
+
+	field.CheckBuildExpr(t, field.NewString("", "name").EqAnySlice([]string{"a", "b", "c"}, field.WithChunkSize(2)), "(`name` = ANY(?) OR `name` = ANY(?))",
+		[]interface{}{field.StringArray{"a", "b"}, field.StringArray{"c"}})
+	if sql, _ = field.BuildToString(id.EqAnySlice([]int64{1, 2}, field.WithChunkSize(2))); sql != "`id` = ANY(?)" {
+		t.Errorf("SQL expects a single ANY got %s", sql)
+	}
+	if sql, _ = field.BuildToString(id.EqAnySlice([]int64{1, 2, 3, 4, 5})); sql != "`id` = ANY(?)" {
+		t.Errorf("SQL expects a single ANY without chunk size got %s", sql)
+	}
+}
+
+func TestCoalesceWithSource(t *testing.T) {
+	mobile, phone := field.NewString("", "mobile"), field.NewString("", "phone")
+	value, source := field.CoalesceWithSource([]field.Expr{mobile, phone, field.NewString("", "email")})
+
+	field.CheckBuildExpr(t, value, "COALESCE(`mobile`, `phone`, `email`)", nil)
+	field.CheckBuildExpr(t, source.Eq("phone"),
+		"CASE WHEN `mobile` IS NOT NULL THEN ? WHEN `phone` IS NOT NULL THEN ? WHEN `email` IS NOT NULL THEN ? ELSE NULL END = ?",
+		[]interface{}{"mobile", "phone", "email", "phone"})
+}
+
+func TestExpr_ExtractInvalidField(t *testing.T) {
+	createdAt := field.NewTime("", "created_at")
+	if err := createdAt.Extract("epoch").CondError(); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	e := createdAt.Extract("year FROM now()) --")
+	if err := e.CondError(); err == nil {
+		t.Errorf("expect error of invalid extract field")
+	}
+	field.CheckBuildExpr(t, e, "EXTRACT(INVALID FROM `created_at`)", nil)
+}
+
+func TestCheckTyping(t *testing.T) {
+	name, age := field.NewString("", "name"), field.NewInt("", "age")
+	five := sql.NullInt64{Int64: 5, Valid: true}
+	mismatches := []field.Expr{
+		field.Field(name).Gt(five),
+		field.Field(age).Eq(sql.NullString{String: "18", Valid: true}),
+		field.Field(field.NewTime("", "created_at")).Lt(sql.NullBool{Bool: true, Valid: true}),
+		field.Or(field.Field(age).Eq(five), field.Field(name).Eq(five)),
+	}
+	matches := []field.Expr{
+		field.Field(name).Gt(sql.NullString{String: "tom", Valid: true}),
+		field.Field(age).Eq(five),
+		field.Field(age).Eq(sql.NullInt64{}),
+		field.Field(age).Gte(columnValuer{field.NewInt("", "min_age")}),
+		field.NewField("", "extra").Eq(five),
+	}
+
+	for _, e := range mismatches {
+		if err := field.CheckTyping(e); err == nil {
+			t.Errorf("expect type mismatch error")
+		}
+		if err := e.CondError(); err != nil {
+			t.Errorf("expect mismatch passed through as condition, got %s", err)
+		}
+	}
+	for _, e := range matches {
+		if err := field.CheckTyping(e); err != nil {
+			t.Errorf("expect no error for matched types, got %s", err)
+		}
+	}
+	field.CheckBuildExpr(t, field.Field(name).Gt(five), "`name` > ?", []interface{}{five})
+}
+
+func TestCheckTyping_derived(t *testing.T) {
+	ended, started, age := field.NewTime("", "ended_at"), field.NewTime("", "started_at"), field.NewInt("", "age")
+	interval := sql.NullString{String: "1 day", Valid: true}
+	derived := []field.Expr{
+		ended.AgeBetween(started).Gt(interval),
+		ended.SubCol(started).(field.Field).Gt(interval),
+	}
+	for _, e := range derived {
+		if err := field.CheckTyping(e); err != nil {
+			t.Errorf("expect derived expression of unknown type not checked, got %s", err)
+		}
+	}
+
+	if err := field.CheckTyping(field.Field(age.Add(1)).Eq(interval)); err == nil {
+		t.Errorf("expect type mismatch error for number arithmetic compared with string")
+	}
+}
+
+func TestExpr_JsonbDeepMerge(t *testing.T) {
+	attrs := field.NewField("", "attrs")
+	if err := attrs.JsonbDeepMerge(`{"a":{"b":1}}`, "jsonb_deep_merge").CondError(); err != nil {
+		t.Errorf("expect valid merge function, got %s", err)
+	}
+	for _, fn := range []string{"", "merge(); DROP TABLE t; --"} {
+		if err := attrs.JsonbDeepMerge(`{"a":{"b":1}}`, fn).CondError(); err == nil {
+			t.Errorf("expect error for merge function %q", fn)
+		}
+	}
+}
+
+func TestExpr_Clamp(t *testing.T) {
+	score := field.NewFloat64("", "score")
+
+	clamped := score.Clamp(0, 100)
+	if err := clamped.CondError(); err != nil {
+		t.Fatalf("expect valid bounds, got %s", err)
+	}
+	field.CheckBuildExpr(t, clamped, "LEAST(GREATEST(`score`,?),?)", []interface{}{0, 100})
+	field.CheckBuildExpr(t, field.NewInt("", "age").Clamp(18, field.NewInt("", "max_age")).Gt(20),
+		"LEAST(GREATEST(`age`,?),`max_age`) > ?", []interface{}{18, 20.0})
+
+	if err := score.Clamp(100, 0.5).CondError(); err == nil {
+		t.Errorf("expect error for min greater than max")
+	}
+}
+
+type namedDialector struct {
+	tests.DummyDialector
+	name string
+}
+
+func (d namedDialector) Name() string { return d.name }
+
+func TestExpr_JsonDialect(t *testing.T) {
+	attrs := field.NewField("", "attrs")
+	testcases := []struct {
+		Expr      field.Expr
+		Postgres  string
+		MySQL     string
+		Vars      []interface{}
+		MySQLVars []interface{}
+	}{
+		{
+			Expr:      attrs.JsonGetField("role"),
+			Postgres:  "`attrs` -> ?",
+			MySQL:     "JSON_EXTRACT(`attrs`, ?)",
+			Vars:      []interface{}{"role"},
+			MySQLVars: []interface{}{`$."role"`},
+		},
+		{
+			Expr:      attrs.JsonGetTextField("role"),
+			Postgres:  "`attrs` ->> ?",
+			MySQL:     "JSON_UNQUOTE(JSON_EXTRACT(`attrs`, ?))",
+			Vars:      []interface{}{"role"},
+			MySQLVars: []interface{}{`$."role"`},
+		},
+		{
+			Expr:      attrs.JsonContains(`{"role":"admin"}`),
+			Postgres:  "`attrs` @> ?",
+			MySQL:     "JSON_CONTAINS(`attrs`, ?)",
+			Vars:      []interface{}{`{"role":"admin"}`},
+			MySQLVars: []interface{}{`{"role":"admin"}`},
+		},
+		{
+			Expr:      attrs.JsonEq([]string{"user", "na\"me"}, "tom"),
+			Postgres:  "`attrs`->'user'->>'na\"me' = ?",
+			MySQL:     "JSON_UNQUOTE(JSON_EXTRACT(`attrs`, ?)) = ?",
+			Vars:      []interface{}{"tom"},
+			MySQLVars: []interface{}{`$."user"."na\"me"`, "tom"},
+		},
+		{
+			Expr:      attrs.JsonSum("score"),
+			Postgres:  "SUM((`attrs`->>'score')::numeric)",
+			MySQL:     "SUM(CAST(JSON_EXTRACT(`attrs`, ?) AS DECIMAL(65,30)))",
+			MySQLVars: []interface{}{`$."score"`},
+		},
+		{
+			Expr:      attrs.JsonValueNull([]string{"user", "name"}),
+			Postgres:  "`attrs`->'user'->>'name' is null",
+			MySQL:     "IFNULL(JSON_TYPE(JSON_EXTRACT(`attrs`, ?)), 'NULL') = 'NULL'",
+			MySQLVars: []interface{}{`$."user"."name"`},
+		},
+		{
+			Expr:      attrs.JsonValueNotNull([]string{"name"}),
+			Postgres:  "`attrs`->>'name' is not null",
+			MySQL:     "IFNULL(JSON_TYPE(JSON_EXTRACT(`attrs`, ?)), 'NULL') <> 'NULL'",
+			MySQLVars: []interface{}{`$."name"`},
+		},
+	}
+
+	build := func(name string, e field.Expr) (string, []interface{}) {
+		db, _ := gorm.Open(namedDialector{name: name}, nil)
+		sql, vars := e.BuildWithArgs(&gorm.Statement{DB: db})
+		return string(sql), vars
+	}
+	for _, testcase := range testcases {
+		if sql, vars := build("postgres", testcase.Expr); sql != testcase.Postgres || !reflect.DeepEqual(vars, testcase.Vars) {
+			t.Errorf("postgres expects %s %v got %s %v", testcase.Postgres, testcase.Vars, sql, vars)
+		}
+		if sql, vars := build("mysql", testcase.Expr); sql != testcase.MySQL || !reflect.DeepEqual(vars, testcase.MySQLVars) {
+			t.Errorf("mysql expects %s %v got %s %v", testcase.MySQL, testcase.MySQLVars, sql, vars)
+		}
+	}
+}
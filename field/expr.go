@@ -2,6 +2,8 @@ package field
 
 import (
 	"fmt"
+	"reflect"
+	"regexp"
 	"strings"
 	"time"
 
@@ -67,10 +69,19 @@ type expr struct {
 
 	e         clause.Expression
 	buildOpts []BuildOpt
+
+	// kind of column values, checked against compared literals by CheckTyping
+	kind valueKind
+
+	// prec binding strength of the top level operator of e, see precedence
+	prec precedence
+
+	// err is reported by CondError, e.g. invalid arguments of the expression
+	err error
 }
 
 func (e expr) BeCond() interface{} { return e.expression() }
-func (expr) CondError() error      { return nil }
+func (e expr) CondError() error    { return e.err }
 
 func (e expr) AssignExpr() expression {
 	return e.expression()
@@ -150,6 +161,9 @@ func (e expr) RawExpr() expression {
 
 func (e expr) setE(expression clause.Expression) expr {
 	e.e = expression
+	e.prec = precUnknown
+	// the value type of a derived expression is unknown, builders knowing it set it by withKind
+	e.kind = kindAny
 	return e
 }
 
@@ -196,10 +210,72 @@ func (e expr) Avg() Float64 {
 	return Float64{e.setE(clause.Expr{SQL: "AVG(?)", Vars: []interface{}{e.RawExpr()}})}
 }
 
+// ArgMax return value of self in the row where valueCol is max, equal to (array_agg(self ORDER BY valueCol DESC))[1]
+func (e expr) ArgMax(valueCol Expr) Expr {
+	return e.setE(clause.Expr{SQL: "(array_agg(? ORDER BY ? DESC))[1]", Vars: []interface{}{e.RawExpr(), valueCol.RawExpr()}})
+}
+
 func (e expr) Abs() Float64 {
 	return Float64{e.setE(clause.Expr{SQL: "ABS(?)", Vars: []interface{}{e.RawExpr()}})}
 }
 
+// Round round self to precision decimal places, equal to ROUND(self, precision)
+func (e expr) Round(precision int) Float64 {
+	return Float64{e.setE(clause.Expr{SQL: "ROUND(?, ?)", Vars: []interface{}{e.RawExpr(), precision}})}
+}
+
+// Ceil smallest integer not less than self, equal to CEIL(self)
+func (e expr) Ceil() Float64 {
+	return Float64{e.setE(clause.Expr{SQL: "CEIL(?)", Vars: []interface{}{e.RawExpr()}})}
+}
+
+// FloorF largest integer not greater than self as Float64, equal to FLOOR(self), see Float64.Floor for Int
+func (e expr) FloorF() Float64 {
+	return Float64{e.floor()}
+}
+
+// Trunc truncate self toward zero to precision decimal places, equal to TRUNC(self, precision)
+func (e expr) Trunc(precision int) Float64 {
+	return Float64{e.setE(clause.Expr{SQL: "TRUNC(?, ?)", Vars: []interface{}{e.RawExpr(), precision}})}
+}
+
+// Power self raised to exp, equal to POWER(self, exp), exp can be a number or another Expr
+func (e expr) Power(exp interface{}) Float64 {
+	return Float64{e.setE(clause.Expr{SQL: "POWER(?, ?)", Vars: []interface{}{e.RawExpr(), toRawValue(exp)}})}
+}
+
+// Sqrt square root of self, equal to SQRT(self)
+func (e expr) Sqrt() Float64 {
+	return Float64{e.setE(clause.Expr{SQL: "SQRT(?)", Vars: []interface{}{e.RawExpr()}})}
+}
+
+// Ln natural logarithm of self, equal to LN(self)
+func (e expr) Ln() Float64 {
+	return Float64{e.setE(clause.Expr{SQL: "LN(?)", Vars: []interface{}{e.RawExpr()}})}
+}
+
+// Log logarithm of self to base, equal to LOG(base, self), base comes first as in postgres and mysql
+func (e expr) Log(base interface{}) Float64 {
+	return Float64{e.setE(clause.Expr{SQL: "LOG(?, ?)", Vars: []interface{}{toRawValue(base), e.RawExpr()}})}
+}
+
+// Exp e raised to self, equal to EXP(self)
+func (e expr) Exp() Float64 {
+	return Float64{e.setE(clause.Expr{SQL: "EXP(?)", Vars: []interface{}{e.RawExpr()}})}
+}
+
+// Clamp bound self between min and max, equal to LEAST(GREATEST(self, min), max),
+// it fails by CondError if both bounds are numbers and min is greater than max
+func (e expr) Clamp(min, max interface{}) Float64 {
+	clamped := e.setE(clause.Expr{SQL: "LEAST(GREATEST(?,?),?)", Vars: []interface{}{e.RawExpr(), toRawValue(min), toRawValue(max)}})
+	if lower, ok := numberValue(min); ok {
+		if upper, ok := numberValue(max); ok && lower > upper {
+			clamped.err = fmt.Errorf("clamp %s with min %v greater than max %v", e.col.Name, min, max)
+		}
+	}
+	return Float64{clamped}
+}
+
 func (e expr) Null() AssignExpr {
 	return e.setE(clause.Eq{Column: e.col.Name, Value: nil})
 }
@@ -208,6 +284,45 @@ func (e expr) GroupConcat() Expr {
 	return e.setE(clause.Expr{SQL: "GROUP_CONCAT(?)", Vars: []interface{}{e.RawExpr()}})
 }
 
+// StringAgg concatenate values separated by delimiter, equal to STRING_AGG(self, delimiter)
+func (e expr) StringAgg(delimiter string) Expr {
+	return e.setE(clause.Expr{SQL: "STRING_AGG(?, ?)", Vars: []interface{}{e.RawExpr(), delimiter}})
+}
+
+// StringAggOrdered concatenate values sorted by order, equal to STRING_AGG(self, delimiter ORDER BY order)
+func (e expr) StringAggOrdered(delimiter string, order ...Expr) Expr {
+	if len(order) == 0 {
+		return e.StringAgg(delimiter)
+	}
+	vars := []interface{}{e.RawExpr(), delimiter}
+	for _, o := range order {
+		vars = append(vars, o.RawExpr())
+	}
+	return e.setE(clause.Expr{SQL: "STRING_AGG(?, ? ORDER BY " + strings.TrimSuffix(strings.Repeat("?,", len(order)), ",") + ")", Vars: vars})
+}
+
+// ArrayAgg aggregate values into an array, equal to ARRAY_AGG(self)
+func (e expr) ArrayAgg() Expr {
+	return e.setE(clause.Expr{SQL: "ARRAY_AGG(?)", Vars: []interface{}{e.RawExpr()}})
+}
+
+// ArrayAggDistinct aggregate distinct values into an array, equal to ARRAY_AGG(DISTINCT self)
+func (e expr) ArrayAggDistinct() Expr {
+	return e.setE(clause.Expr{SQL: "ARRAY_AGG(DISTINCT ?)", Vars: []interface{}{e.RawExpr()}})
+}
+
+// ArrayAggOrdered aggregate values into an array sorted by order, equal to ARRAY_AGG(self ORDER BY order)
+func (e expr) ArrayAggOrdered(order ...Expr) Expr {
+	if len(order) == 0 {
+		return e.ArrayAgg()
+	}
+	vars := []interface{}{e.RawExpr()}
+	for _, o := range order {
+		vars = append(vars, o.RawExpr())
+	}
+	return e.setE(clause.Expr{SQL: "ARRAY_AGG(? ORDER BY " + strings.TrimSuffix(strings.Repeat("?,", len(order)), ",") + ")", Vars: vars})
+}
+
 // ======================== comparison between columns ========================
 func (e expr) EqCol(col Expr) Expr {
 	return e.setE(clause.Expr{SQL: "? = ?", Vars: []interface{}{e.RawExpr(), col.RawExpr()}})
@@ -242,20 +357,87 @@ func (e expr) ReverseIncludeTextCol(col Expr) Expr {
 }
 
 // ======================== operate columns ========================
+
+// precedence binding strength of the top level operator of an expression,
+// arithmetic builders parenthesize an operand which binds weaker than their operator
+type precedence uint8
+
+const (
+	// precUnknown expression whose operator is not tracked, e.g. a function call or a comparison
+	precUnknown precedence = iota
+	// precAdditive a + b, a - b
+	precAdditive
+	// precMultiplicative a * b, a / b
+	precMultiplicative
+	// precAtom column or parenthesized expression
+	precAtom
+)
+
+func (e expr) precedence() precedence {
+	if e.e == nil {
+		return precAtom
+	}
+	return e.prec
+}
+
+// withKind set kind of values of a derived expression whose type is known, e.g. a number plus a number
+func (e expr) withKind(kind valueKind) expr {
+	e.kind = kind
+	return e
+}
+
+func (e expr) withPrecedence(prec precedence) expr {
+	e.prec = prec
+	return e
+}
+
+// precedenceOf return precedence of col, it's unknown for expressions not built by this package
+func precedenceOf(col Expr) precedence {
+	if p, ok := col.(interface{ precedence() precedence }); ok {
+		return p.precedence()
+	}
+	return precUnknown
+}
+
+// operand return raw expression of col, parenthesized if it binds weaker than prec
+func operand(col Expr, prec precedence) interface{} {
+	if precedenceOf(col) < prec {
+		return parenthesize(col.RawExpr())
+	}
+	return col.RawExpr()
+}
+
 func (e expr) AddCol(col Expr) Expr {
-	return Field{e.setE(clause.Expr{SQL: "? + ?", Vars: []interface{}{e.RawExpr(), col.RawExpr()}})}
+	return Field{e.setE(clause.Expr{SQL: "? + ?", Vars: []interface{}{e.RawExpr(), col.RawExpr()}}).withPrecedence(precAdditive)}
 }
 
+// SubCol equal to self - col, col is parenthesized if it is a sum or difference, e.g. a - (b + c)
 func (e expr) SubCol(col Expr) Expr {
-	return Field{e.setE(clause.Expr{SQL: "? - ?", Vars: []interface{}{e.RawExpr(), col.RawExpr()}})}
+	right := col.RawExpr()
+	if precedenceOf(col) == precAdditive {
+		right = parenthesize(right)
+	}
+	return Field{e.setE(clause.Expr{SQL: "? - ?", Vars: []interface{}{e.RawExpr(), right}}).withPrecedence(precAdditive)}
 }
 
+// MulCol equal to self * col, an operand is parenthesized only if it binds weaker than the multiplication,
+// e.g. (a + b) * c and a * b * c, instead of wrapping each operand like ((a + b) * (c))
 func (e expr) MulCol(col Expr) Expr {
-	return Field{e.setE(clause.Expr{SQL: "(?) * (?)", Vars: []interface{}{e.RawExpr(), col.RawExpr()}})}
+	return Field{e.setE(clause.Expr{SQL: "? * ?", Vars: []interface{}{operand(e, precMultiplicative), operand(col, precAtom)}}).withPrecedence(precMultiplicative)}
 }
 
+// DivCol equal to self / col, operands are parenthesized like MulCol, e.g. (a + b) / c and a / (b * c)
 func (e expr) DivCol(col Expr) Expr {
-	return Field{e.setE(clause.Expr{SQL: "(?) / (?)", Vars: []interface{}{e.RawExpr(), col.RawExpr()}})}
+	return Field{e.setE(clause.Expr{SQL: "? / ?", Vars: []interface{}{operand(e, precMultiplicative), operand(col, precAtom)}}).withPrecedence(precMultiplicative)}
+}
+
+// Parenthesize wrap the whole expression in parentheses, e.g. (a + b)
+func (e expr) Parenthesize() Field {
+	return Field{e.setE(parenthesize(e.RawExpr())).withPrecedence(precAtom)}
+}
+
+func parenthesize(raw interface{}) clause.Expr {
+	return clause.Expr{SQL: "(?)", Vars: []interface{}{raw}}
 }
 
 func (e expr) ConcatCol(cols ...Expr) Expr {
@@ -274,12 +456,16 @@ func (e expr) ConcatCol(cols ...Expr) Expr {
 // ======================== keyword ========================
 func (e expr) As(alias string) Expr {
 	if e.e != nil {
+		e.col.Alias = alias
 		return e.setE(clause.Expr{SQL: "? AS ?", Vars: []interface{}{e.e, clause.Column{Name: alias}}})
 	}
 	e.col.Alias = alias
 	return e
 }
 
+// Alias return the alias set by As, empty if not aliased
+func (e expr) Alias() string { return e.col.Alias }
+
 // Desc sort by desc
 func (e expr) Desc() Expr {
 	return e.setE(clause.Expr{SQL: "? DESC", Vars: []interface{}{e.RawExpr()}})
@@ -306,40 +492,40 @@ func (e expr) between(values []interface{}) expr {
 func (e expr) add(value interface{}) expr {
 	switch v := value.(type) {
 	case time.Duration:
-		return e.setE(clause.Expr{SQL: "DATE_ADD(?, INTERVAL ? MICROSECOND)", Vars: []interface{}{e.RawExpr(), v.Microseconds()}})
+		return e.setE(clause.Expr{SQL: "DATE_ADD(?, INTERVAL ? MICROSECOND)", Vars: []interface{}{e.RawExpr(), v.Microseconds()}}).withKind(e.kind)
 	default:
-		return e.setE(clause.Expr{SQL: "?+?", Vars: []interface{}{e.RawExpr(), value}})
+		return e.setE(clause.Expr{SQL: "?+?", Vars: []interface{}{e.RawExpr(), value}}).withPrecedence(precAdditive).withKind(e.kind)
 	}
 }
 
 func (e expr) sub(value interface{}) expr {
 	switch v := value.(type) {
 	case time.Duration:
-		return e.setE(clause.Expr{SQL: "DATE_SUB(?, INTERVAL ? MICROSECOND)", Vars: []interface{}{e.RawExpr(), v.Microseconds()}})
+		return e.setE(clause.Expr{SQL: "DATE_SUB(?, INTERVAL ? MICROSECOND)", Vars: []interface{}{e.RawExpr(), v.Microseconds()}}).withKind(e.kind)
 	default:
-		return e.setE(clause.Expr{SQL: "?-?", Vars: []interface{}{e.RawExpr(), value}})
+		return e.setE(clause.Expr{SQL: "?-?", Vars: []interface{}{e.RawExpr(), value}}).withPrecedence(precAdditive).withKind(e.kind)
 	}
 }
 
 func (e expr) mul(value interface{}) expr {
 	if e.isPure() {
-		return e.setE(clause.Expr{SQL: "?*?", Vars: []interface{}{e.col, value}})
+		return e.setE(clause.Expr{SQL: "?*?", Vars: []interface{}{e.col, value}}).withPrecedence(precMultiplicative).withKind(e.kind)
 	}
-	return e.setE(clause.Expr{SQL: "(?)*?", Vars: []interface{}{e.e, value}})
+	return e.setE(clause.Expr{SQL: "(?)*?", Vars: []interface{}{e.e, value}}).withPrecedence(precMultiplicative).withKind(e.kind)
 }
 
 func (e expr) div(value interface{}) expr {
 	if e.isPure() {
-		return e.setE(clause.Expr{SQL: "?/?", Vars: []interface{}{e.col, value}})
+		return e.setE(clause.Expr{SQL: "?/?", Vars: []interface{}{e.col, value}}).withPrecedence(precMultiplicative).withKind(e.kind)
 	}
-	return e.setE(clause.Expr{SQL: "(?)/?", Vars: []interface{}{e.e, value}})
+	return e.setE(clause.Expr{SQL: "(?)/?", Vars: []interface{}{e.e, value}}).withPrecedence(precMultiplicative).withKind(e.kind)
 }
 
 func (e expr) mod(value interface{}) expr {
 	if e.isPure() {
-		return e.setE(clause.Expr{SQL: "?%?", Vars: []interface{}{e.col, value}})
+		return e.setE(clause.Expr{SQL: "?%?", Vars: []interface{}{e.col, value}}).withKind(e.kind)
 	}
-	return e.setE(clause.Expr{SQL: "(?)%?", Vars: []interface{}{e.e, value}})
+	return e.setE(clause.Expr{SQL: "(?)%?", Vars: []interface{}{e.e, value}}).withKind(e.kind)
 }
 
 func (e expr) floorDiv(value interface{}) expr {
@@ -439,8 +625,34 @@ func (e expr) DateValueBetweenCol(value interface{}, col Expr) expr {
 	return e.setE(clause.Expr{SQL: "? between ? and ? + INTERVAL '1 days' * ?", Vars: []interface{}{value, e.RawExpr(), e.RawExpr(), col.RawExpr()}})
 }
 
-func (e expr) Coalesce(col Expr) expr {
-	return e.setE(clause.Expr{SQL: "COALESCE(?,?)", Vars: []interface{}{e.RawExpr(), col.RawExpr()}})
+// Coalesce equal to COALESCE(self, values...), a value can be a scalar or another Expr
+func (e expr) Coalesce(values ...interface{}) Expr {
+	return e.setE(e.coalesce(values))
+}
+
+func (e expr) coalesce(values []interface{}) clause.Expr {
+	return e.variadicFunc("COALESCE", values)
+}
+
+// Greatest largest of self and values, equal to GREATEST(self, values...), a value can be a scalar or another Expr
+func (e expr) Greatest(values ...interface{}) Expr {
+	return e.setE(e.variadicFunc("GREATEST", values))
+}
+
+// Least smallest of self and values, equal to LEAST(self, values...), a value can be a scalar or another Expr
+func (e expr) Least(values ...interface{}) Expr {
+	return e.setE(e.variadicFunc("LEAST", values))
+}
+
+// variadicFunc equal to fn(self, values...)
+func (e expr) variadicFunc(fn string, values []interface{}) clause.Expr {
+	placeholders := []string{"?"}
+	vars := []interface{}{e.RawExpr()}
+	for _, value := range values {
+		placeholders = append(placeholders, "?")
+		vars = append(vars, toRawValue(value))
+	}
+	return clause.Expr{SQL: fn + "(" + strings.Join(placeholders, ",") + ")", Vars: vars}
 }
 
 func (e expr) Include(value interface{}) expr {
@@ -459,40 +671,40 @@ func (e expr) ReverseLike(value interface{}) expr {
 	return e.setE(clause.Expr{SQL: "? LIKE '%' || ? || '%'", Vars: []interface{}{value, e.RawExpr()}})
 }
 
+// JsonSum equal to SUM((?->>'field')::numeric), SUM(CAST(JSON_EXTRACT(?, '$.field') AS DECIMAL(65,30))) on mysql
 func (e expr) JsonSum(field string) expr {
 	rawExpr := fmt.Sprintf("SUM((?->>'%s')::numeric)", field)
-	return e.setE(clause.Expr{SQL: rawExpr, Vars: []interface{}{e.RawExpr()}})
+	return e.setE(dialectExpr{def: clause.Expr{SQL: rawExpr, Vars: []interface{}{e.RawExpr()}}, exprs: map[string]clause.Expr{
+		"mysql": {SQL: "SUM(CAST(JSON_EXTRACT(?, ?) AS DECIMAL(65,30)))", Vars: []interface{}{e.RawExpr(), jsonPath(field)}},
+	}})
 }
 
 func (e expr) JsonEq(paths []string, value interface{}) expr {
-	var _paths []string
-	for _, path := range paths {
-		_paths = append(_paths, "'"+path+"'")
-	}
-	indexPath := len(_paths) - 1
-	pathStr := strings.Join(_paths[:indexPath], "->")
-	if len(pathStr) > 0 {
-		pathStr = "->"+pathStr
-	}
-	pathStr += "->>" + _paths[indexPath]
-	return e.setE(clause.Expr{SQL: "?"+pathStr+" = ?", Vars: []interface{}{e.RawExpr(), value}})
+	pg := clause.Expr{SQL: "?" + jsonTextPath(paths) + " = ?", Vars: []interface{}{e.RawExpr(), value}}
+	return e.setE(dialectExpr{def: pg, exprs: map[string]clause.Expr{
+		"mysql": {SQL: "JSON_UNQUOTE(JSON_EXTRACT(?, ?)) = ?", Vars: []interface{}{e.RawExpr(), jsonPath(paths...), value}},
+	}})
 }
 
+// JsonValueNull the value at paths is missing or json null,
+// the type of the value is checked on mysql since JSON_EXTRACT returns json null instead of NULL
 func (e expr) JsonValueNull(paths []string) expr {
-	var _paths []string
-	for _, path := range paths {
-		_paths = append(_paths, "'"+path+"'")
-	}
-	indexPath := len(_paths) - 1
-	pathStr := strings.Join(_paths[:indexPath], "->")
-	if len(pathStr) > 0 {
-		pathStr = "->"+pathStr
-	}
-	pathStr += "->>" + _paths[indexPath]
-	return e.setE(clause.Expr{SQL: "?"+pathStr+" is null", Vars: []interface{}{e.RawExpr()}})
+	pg := clause.Expr{SQL: "?" + jsonTextPath(paths) + " is null", Vars: []interface{}{e.RawExpr()}}
+	return e.setE(dialectExpr{def: pg, exprs: map[string]clause.Expr{
+		"mysql": {SQL: "IFNULL(JSON_TYPE(JSON_EXTRACT(?, ?)), 'NULL') = 'NULL'", Vars: []interface{}{e.RawExpr(), jsonPath(paths...)}},
+	}})
 }
 
+// JsonValueNotNull the value at paths exists and is not json null
 func (e expr) JsonValueNotNull(paths []string) expr {
+	pg := clause.Expr{SQL: "?" + jsonTextPath(paths) + " is not null", Vars: []interface{}{e.RawExpr()}}
+	return e.setE(dialectExpr{def: pg, exprs: map[string]clause.Expr{
+		"mysql": {SQL: "IFNULL(JSON_TYPE(JSON_EXTRACT(?, ?)), 'NULL') <> 'NULL'", Vars: []interface{}{e.RawExpr(), jsonPath(paths...)}},
+	}})
+}
+
+// jsonTextPath postgres path operators of paths with the last value as text, e.g. ->'a'->>'b'
+func jsonTextPath(paths []string) string {
 	var _paths []string
 	for _, path := range paths {
 		_paths = append(_paths, "'"+path+"'")
@@ -500,10 +712,9 @@ func (e expr) JsonValueNotNull(paths []string) expr {
 	indexPath := len(_paths) - 1
 	pathStr := strings.Join(_paths[:indexPath], "->")
 	if len(pathStr) > 0 {
-		pathStr = "->"+pathStr
+		pathStr = "->" + pathStr
 	}
-	pathStr += "->>" + _paths[indexPath]
-	return e.setE(clause.Expr{SQL: "?"+pathStr+" is not null", Vars: []interface{}{e.RawExpr()}})
+	return pathStr + "->>" + _paths[indexPath]
 }
 
 func (e expr) ArrayContains(expr interface{}) Expr {
@@ -518,22 +729,192 @@ func (e expr) ArrayOverlap(expr interface{}) Expr {
 	return e.setE(clause.Expr{SQL: "? && ?", Vars: []interface{}{e.RawExpr(), expr}})
 }
 
+// ArrayLength length of the array dimension dim, equal to array_length(self, dim)
+func (e expr) ArrayLength(dim int) Int {
+	return Int{e.setE(clause.Expr{SQL: "array_length(?, ?)", Vars: []interface{}{e.RawExpr(), dim}})}
+}
+
+// ArrayPosition 1-based position of the first element equal to value, equal to array_position(self, value)
+func (e expr) ArrayPosition(value interface{}) Int {
+	return Int{e.setE(clause.Expr{SQL: "array_position(?, ?)", Vars: []interface{}{e.RawExpr(), toRawValue(value)}})}
+}
+
+// ArrayRemove remove all elements equal to value, equal to array_remove(self, value)
+func (e expr) ArrayRemove(value interface{}) Expr {
+	return e.setE(clause.Expr{SQL: "array_remove(?, ?)", Vars: []interface{}{e.RawExpr(), toRawValue(value)}})
+}
+
+// CoalesceArray return def if array is NULL or empty, equal to COALESCE(NULLIF(self, '{}'), def),
+// def can be another Expr or a Go slice bound as a single array
+func (e expr) CoalesceArray(def interface{}) Expr {
+	return e.setE(clause.Expr{SQL: "COALESCE(NULLIF(?, '{}'), ?)", Vars: []interface{}{e.RawExpr(), toArray(toRawValue(def))}})
+}
+
+// EqAnySlice equal to any element of Go slice values, the slice is bound as a single array parameter,
+// prefer it over In for large lists which may exceed the bind var limit, equal to "? = ANY(?)"
+// values longer than the size of WithChunkSize are split into arrays of at most that size, equal to "(? = ANY(?) OR ? = ANY(?))"
+func (e expr) EqAnySlice(values interface{}, opts ...AnySliceOption) Expr {
+	var config anySliceConfig
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	rv := reflect.ValueOf(values)
+	size := config.chunkSize
+	if size <= 0 || rv.Kind() != reflect.Slice || rv.Len() <= size {
+		return e.setE(clause.Expr{SQL: "? = ANY(?)", Vars: []interface{}{e.RawExpr(), toArray(values)}})
+	}
+
+	var conds []string
+	var vars []interface{}
+	for i := 0; i < rv.Len(); i += size {
+		end := i + size
+		if end > rv.Len() {
+			end = rv.Len()
+		}
+		conds = append(conds, "? = ANY(?)")
+		vars = append(vars, e.RawExpr(), toArray(rv.Slice(i, end).Interface()))
+	}
+	return e.setE(clause.Expr{SQL: "(" + strings.Join(conds, " OR ") + ")", Vars: vars})
+}
+
+// AnySliceOption option of EqAnySlice
+type AnySliceOption func(*anySliceConfig)
+
+type anySliceConfig struct {
+	chunkSize int
+}
+
+// WithChunkSize bind at most size elements as one array, 0 means no limit,
+// set it for drivers limiting the size of array parameters
+func WithChunkSize(size int) AnySliceOption {
+	return func(c *anySliceConfig) { c.chunkSize = size }
+}
+
+// EqAny equal to any element of array, equal to "? = ANY(?)"
+func (e expr) EqAny(array interface{}) Expr {
+	return e.setE(clause.Expr{SQL: "? = ANY(?)", Vars: []interface{}{e.RawExpr(), toRawValue(array)}})
+}
+
+// NotEqAny negate the whole ANY predicate, equal to "NOT (? = ANY(?))"
+func (e expr) NotEqAny(array interface{}) Expr {
+	return e.setE(clause.Expr{SQL: "NOT (? = ANY(?))", Vars: []interface{}{e.RawExpr(), toRawValue(array)}})
+}
+
+// NeqAll not equal to every element of array, equal to "? <> ALL(?)"
+func (e expr) NeqAll(array interface{}) Expr {
+	return e.setE(clause.Expr{SQL: "? <> ALL(?)", Vars: []interface{}{e.RawExpr(), toRawValue(array)}})
+}
+
+// JsonGetField equal to "? -> ?", JSON_EXTRACT(?, '$.field') on mysql
 func (e expr) JsonGetField(field string) Expr {
-	return e.setE(clause.Expr{SQL: "? -> ?", Vars: []interface{}{e.RawExpr(), field}})
+	return e.setE(dialectExpr{
+		def: clause.Expr{SQL: "? -> ?", Vars: []interface{}{e.RawExpr(), field}},
+		exprs: map[string]clause.Expr{
+			"mysql": {SQL: "JSON_EXTRACT(?, ?)", Vars: []interface{}{e.RawExpr(), jsonPath(field)}},
+		},
+	})
 }
 
+// JsonGetTextField equal to "? ->> ?", JSON_UNQUOTE(JSON_EXTRACT(?, '$.field')) on mysql
 func (e expr) JsonGetTextField(field string) Expr {
-	return e.setE(clause.Expr{SQL: "? ->> ?", Vars: []interface{}{e.RawExpr(), field}})
+	return e.setE(dialectExpr{
+		def: clause.Expr{SQL: "? ->> ?", Vars: []interface{}{e.RawExpr(), field}},
+		exprs: map[string]clause.Expr{
+			"mysql": {SQL: "JSON_UNQUOTE(JSON_EXTRACT(?, ?))", Vars: []interface{}{e.RawExpr(), jsonPath(field)}},
+		},
+	})
 }
 
+// JsonContains equal to "? @> ?", JSON_CONTAINS(?, ?) on mysql
 func (e expr) JsonContains(value interface{}) Expr {
-	return e.setE(clause.Expr{SQL: "? @> ?", Vars: []interface{}{e.RawExpr(), value}})
+	return e.setE(dialectExpr{
+		def: clause.Expr{SQL: "? @> ?", Vars: []interface{}{e.RawExpr(), value}},
+		exprs: map[string]clause.Expr{
+			"mysql": {SQL: "JSON_CONTAINS(?, ?)", Vars: []interface{}{e.RawExpr(), value}},
+		},
+	})
+}
+
+// JsonbHasKey jsonb contains the top-level key, equal to "? ? ?" with the operator written literally
+func (e expr) JsonbHasKey(key string) Expr {
+	return e.setE(escapeExpr("? ?? ?", e.RawExpr(), key))
+}
+
+// JsonbHasAnyKey jsonb contains any of the top-level keys, equal to "? ?| ARRAY[?]"
+func (e expr) JsonbHasAnyKey(keys []string) Expr {
+	ex := escapeExpr("? ??| ARRAY[?]", e.RawExpr(), keys)
+	ex.WithoutParentheses = true
+	return e.setE(ex)
+}
+
+// JsonbHasAllKeys jsonb contains all of the top-level keys, equal to "? ?& ARRAY[?]"
+func (e expr) JsonbHasAllKeys(keys []string) Expr {
+	ex := escapeExpr("? ??& ARRAY[?]", e.RawExpr(), keys)
+	ex.WithoutParentheses = true
+	return e.setE(ex)
+}
+
+// JsonPathExists jsonb path returns any item, equal to "? @? ?::jsonpath"
+func (e expr) JsonPathExists(path string) Expr {
+	return e.setE(escapeExpr("? @?? ?::jsonpath", e.RawExpr(), path))
+}
+
+// JsonPathMatch jsonb path predicate is true, equal to "? @@ ?::jsonpath"
+func (e expr) JsonPathMatch(path string) Expr {
+	return e.setE(clause.Expr{SQL: "? @@ ?::jsonpath", Vars: []interface{}{e.RawExpr(), path}})
+}
+
+// Unnest expand array into a set of rows, equal to UNNEST(self)
+func (e expr) Unnest() Expr {
+	return e.setE(clause.Expr{SQL: "UNNEST(?)", Vars: []interface{}{e.RawExpr()}})
+}
+
+// UnnestWithOrdinality expand array into rows numbered from 1, equal to UNNEST(self) WITH ORDINALITY.
+// only valid in FROM, e.g. CrossJoinLateralFunc(tags.UnnestWithOrdinality(), "t", "tag", "ord")
+func (e expr) UnnestWithOrdinality() Expr {
+	return e.setE(clause.Expr{SQL: "UNNEST(?) WITH ORDINALITY", Vars: []interface{}{e.RawExpr()}})
 }
 
 func (e expr) JsonbArrayLength() Expr {
 	return e.setE(clause.Expr{SQL: "jsonb_array_length(?)", Vars: []interface{}{e.RawExpr()}})
 }
 
+// JsonbSet assign self with value set at path, equal to jsonb_set(self, '{a,b}', ?)
+func (e expr) JsonbSet(path []string, value interface{}) AssignExpr {
+	return e.setE(clause.Eq{Column: e.col.Name, Value: clause.Expr{
+		SQL:  "jsonb_set(?, ?, ?)",
+		Vars: []interface{}{e.RawExpr(), textArrayLiteral(path), toRawValue(value)},
+	}})
+}
+
+// JsonbInsert assign self with value inserted at path, equal to jsonb_insert(self, '{a,b}', ?)
+func (e expr) JsonbInsert(path []string, value interface{}) AssignExpr {
+	return e.setE(clause.Eq{Column: e.col.Name, Value: clause.Expr{
+		SQL:  "jsonb_insert(?, ?, ?)",
+		Vars: []interface{}{e.RawExpr(), textArrayLiteral(path), toRawValue(value)},
+	}})
+}
+
+// JsonbShallowMerge merge value into the top level of self, equal to "? || ?".
+// Keys of value replace the same keys of self entirely, nested objects are not merged
+func (e expr) JsonbShallowMerge(value interface{}) Expr {
+	return e.setE(clause.Expr{SQL: "? || ?", Vars: []interface{}{e.RawExpr(), toRawValue(value)}})
+}
+
+// JsonbDeepMerge merge value into self recursively by mergeFunc, equal to mergeFunc(self, ?).
+// a recursive merge is not built into postgres, mergeFunc names the function provided by an extension
+// or a user defined function, e.g. "jsonb_deep_merge", use JsonbShallowMerge when only top level keys are merged
+func (e expr) JsonbDeepMerge(value interface{}, mergeFunc string) Expr {
+	merged := e.setE(clause.Expr{SQL: mergeFunc + "(?, ?)", Vars: []interface{}{e.RawExpr(), toRawValue(value)}})
+	if !funcNameReg.MatchString(mergeFunc) {
+		merged.err = fmt.Errorf("invalid deep merge function %q of %s", mergeFunc, e.col.Name)
+	}
+	return merged
+}
+
+var funcNameReg = regexp.MustCompile(`^\w+(\.\w+)?$`)
+
 func (e expr) RegexpMatch(pattern string) Expr {
 	return e.setE(clause.Expr{SQL: "? ~ ?", Vars: []interface{}{e.RawExpr(), pattern}})
 }
@@ -542,6 +923,23 @@ func (e expr) IRegexpMatch(pattern string) Expr {
 	return e.setE(clause.Expr{SQL: "? ~* ?", Vars: []interface{}{e.RawExpr(), pattern}})
 }
 
+// DateTrunc truncate self to the precision part, e.g. "month", equal to DATE_TRUNC(part, self), part is bound as var
+func (e expr) DateTrunc(part string) Time {
+	return Time{e.setE(clause.Expr{SQL: "DATE_TRUNC(?,?)", Vars: []interface{}{part, e.RawExpr()}})}
+}
+
+// Extract equal to EXTRACT(field FROM self), e.g. Extract("year").Gt(2020),
+// field is a keyword which can not be bound as var, so it must consist of letters and underscores, or CondError fails
+func (e expr) Extract(field string) Float64 {
+	unit, err := rawSQL(field), error(nil)
+	if field == "" || strings.TrimLeft(strings.ToLower(field), "abcdefghijklmnopqrstuvwxyz_") != "" {
+		unit, err = "INVALID", fmt.Errorf("invalid extract field %q of %s", field, e.col.Name)
+	}
+	extracted := e.setE(clause.Expr{SQL: "EXTRACT(? FROM ?)", Vars: []interface{}{unit, e.RawExpr()}})
+	extracted.err = err
+	return Float64{extracted}
+}
+
 func (e expr) DatePart(field string) Expr {
 	return e.setE(clause.Expr{SQL: "DATE_PART(?, ?)", Vars: []interface{}{field, e.RawExpr()}})
 }
@@ -550,6 +948,12 @@ func (e expr) Age() Expr {
 	return e.setE(clause.Expr{SQL: "AGE(?)", Vars: []interface{}{e.RawExpr()}})
 }
 
+// AgeBetween interval from other to self, equal to AGE(self, other),
+// it returns Field so that parts of the interval can be taken by Extract or DatePart
+func (e expr) AgeBetween(other Expr) Field {
+	return Field{e.setE(clause.Expr{SQL: "AGE(?, ?)", Vars: []interface{}{e.RawExpr(), other.RawExpr()}})}
+}
+
 func (e expr) Now() Expr {
 	return e.setE(clause.Expr{SQL: "CURRENT_TIMESTAMP", Vars: nil})
 }
@@ -578,17 +982,23 @@ func (e expr) DistinctOn() Expr {
 	return e.setE(clause.Expr{SQL: "DISTINCT ON (?)", Vars: []interface{}{e.RawExpr()}})
 }
 
-func (e expr) CaseWhen(conditions []Expr, results []Expr) Expr {
-	var cases []string
-	var vars []interface{}
+// CaseWhen equal to CASE WHEN condition1 THEN result1 ... ELSE elseValue END,
+// ELSE NULL is used without elseValue, elseValue can be a scalar or another Expr
+func (e expr) CaseWhen(conditions []Expr, results []Expr, elseValue ...interface{}) Expr {
+	var sql strings.Builder
+	vars := make([]interface{}, 0, 2*len(conditions)+1)
+	sql.WriteString("CASE")
 	for i, condition := range conditions {
-		cases = append(cases, fmt.Sprintf("WHEN %s THEN %s", condition.RawExpr(), results[i].RawExpr()))
+		sql.WriteString(" WHEN ? THEN ?")
 		vars = append(vars, condition.RawExpr(), results[i].RawExpr())
 	}
-	return e.setE(clause.Expr{
-		SQL:  "CASE " + strings.Join(cases, " ") + " ELSE NULL END",
-		Vars: vars,
-	})
+	if len(elseValue) > 0 {
+		sql.WriteString(" ELSE ? END")
+		vars = append(vars, toRawValue(elseValue[0]))
+	} else {
+		sql.WriteString(" ELSE NULL END")
+	}
+	return e.setE(clause.Expr{SQL: sql.String(), Vars: vars})
 }
 
 func (e expr) NullIf(value interface{}) Expr {
@@ -607,6 +1017,90 @@ func (e expr) Trim() Expr {
 	return e.setE(clause.Expr{SQL: "TRIM(?)", Vars: []interface{}{e.RawExpr()}})
 }
 
+// Replace equal to REPLACE(self, from, to), from and to are bound as vars
+func (e expr) Replace(from, to string) Expr {
+	return e.setE(clause.Expr{SQL: "REPLACE(?,?,?)", Vars: []interface{}{e.RawExpr(), from, to}})
+}
+
+// Substring equal to SUBSTRING(self FROM start FOR length), FOR is omitted without length
+func (e expr) Substring(start int, length ...int) Expr {
+	if len(length) == 0 {
+		return e.setE(clause.Expr{SQL: "SUBSTRING(? FROM ?)", Vars: []interface{}{e.RawExpr(), start}})
+	}
+	return e.setE(clause.Expr{SQL: "SUBSTRING(? FROM ? FOR ?)", Vars: []interface{}{e.RawExpr(), start, length[0]}})
+}
+
+// rawSQL is written into the statement as is, it is used as a var for operators containing "?",
+// which would be taken as a placeholder if they were part of clause.Expr's SQL
+type rawSQL string
+
+func (r rawSQL) Build(builder clause.Builder) { _, _ = builder.WriteString(string(r)) }
+
+// escapeExpr build clause.Expr from sql in which "??" stands for a literal question mark,
+// the literal one is moved into vars so that it doesn't consume a bind var of its own
+func escapeExpr(sql string, vars ...interface{}) clause.Expr {
+	if !strings.Contains(sql, "??") {
+		return clause.Expr{SQL: sql, Vars: vars}
+	}
+
+	var (
+		query   strings.Builder
+		escaped = make([]interface{}, 0, len(vars)+1)
+		idx     int
+	)
+	for i := 0; i < len(sql); i++ {
+		query.WriteByte(sql[i])
+		if sql[i] != '?' {
+			continue
+		}
+		if i+1 < len(sql) && sql[i+1] == '?' {
+			escaped = append(escaped, rawSQL("?"))
+			i++
+			continue
+		}
+		if idx < len(vars) {
+			escaped = append(escaped, vars[idx])
+			idx++
+		}
+	}
+	return clause.Expr{SQL: query.String(), Vars: append(escaped, vars[idx:]...)}
+}
+
+// dialectExpr expression written in the syntax of the dialect of statement at build time,
+// def is the postgres syntax which is used by dialects not in exprs
+type dialectExpr struct {
+	def   clause.Expr
+	exprs map[string]clause.Expr
+}
+
+func (d dialectExpr) Build(builder clause.Builder) {
+	e := d.def
+	if stmt, ok := builder.(*gorm.Statement); ok {
+		if native, ok := d.exprs[stmt.Dialector.Name()]; ok {
+			e = native
+		}
+	}
+	e.Build(builder)
+}
+
+// jsonPath mysql json path of keys, e.g. $."a"."b"
+func jsonPath(keys ...string) string {
+	var path strings.Builder
+	path.WriteByte('$')
+	for _, key := range keys {
+		path.WriteString(`."` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(key) + `"`)
+	}
+	return path.String()
+}
+
+// toRawValue unwrap Expr to its raw expression, so that a column is referenced instead of being bound as a value
+func toRawValue(value interface{}) interface{} {
+	if e, ok := value.(Expr); ok {
+		return e.RawExpr()
+	}
+	return value
+}
+
 // NewExpr creates a new expression with alias and clause
 func NewExpr(alias string, expression clause.Expression) Expr {
 	return expr{
@@ -2,6 +2,9 @@ package field
 
 import (
 	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"time"
 
 	"gorm.io/gorm/clause"
 )
@@ -13,16 +16,19 @@ type ScanValuer interface {
 }
 
 // Field a standard field struct
+//
+// a driver.Valuer passed to comparison methods which is also an Expr,
+// e.g. a custom column type, is compared as a column reference instead of being bound as a value
 type Field struct{ expr }
 
 // Eq judge equal
 func (field Field) Eq(value driver.Valuer) Expr {
-	return expr{e: clause.Eq{Column: field.RawExpr(), Value: value}}
+	return field.compare(clause.Eq{Column: field.RawExpr(), Value: toRawValue(value)}, value)
 }
 
 // Neq judge not equal
 func (field Field) Neq(value driver.Valuer) Expr {
-	return expr{e: clause.Neq{Column: field.RawExpr(), Value: value}}
+	return field.compare(clause.Neq{Column: field.RawExpr(), Value: toRawValue(value)}, value)
 }
 
 // In ...
@@ -37,27 +43,27 @@ func (field Field) NotIn(values ...driver.Valuer) Expr {
 
 // Gt ...
 func (field Field) Gt(value driver.Valuer) Expr {
-	return expr{e: clause.Gt{Column: field.RawExpr(), Value: value}}
+	return field.compare(clause.Gt{Column: field.RawExpr(), Value: toRawValue(value)}, value)
 }
 
 // Gte ...
 func (field Field) Gte(value driver.Valuer) Expr {
-	return expr{e: clause.Gte{Column: field.RawExpr(), Value: value}}
+	return field.compare(clause.Gte{Column: field.RawExpr(), Value: toRawValue(value)}, value)
 }
 
 // Lt ...
 func (field Field) Lt(value driver.Valuer) Expr {
-	return expr{e: clause.Lt{Column: field.RawExpr(), Value: value}}
+	return field.compare(clause.Lt{Column: field.RawExpr(), Value: toRawValue(value)}, value)
 }
 
 // Lte ...
 func (field Field) Lte(value driver.Valuer) Expr {
-	return expr{e: clause.Lte{Column: field.RawExpr(), Value: value}}
+	return field.compare(clause.Lte{Column: field.RawExpr(), Value: toRawValue(value)}, value)
 }
 
 // Like ...
 func (field Field) Like(value driver.Valuer) Expr {
-	return expr{e: clause.Like{Column: field.RawExpr(), Value: value}}
+	return field.compare(clause.Like{Column: field.RawExpr(), Value: toRawValue(value)}, value)
 }
 
 // Value ...
@@ -76,7 +82,7 @@ func (field Field) IfNull(value driver.Valuer) Expr {
 }
 
 func (e expr) Add(value interface{}) Expr {
-	return Field{e.setE(clause.Expr{SQL: "? + ?", Vars: []interface{}{e.RawExpr(), value}})}
+	return Field{e.setE(clause.Expr{SQL: "? + ?", Vars: []interface{}{e.RawExpr(), value}}).withPrecedence(precAdditive)}
 }
 
 // Field ...
@@ -91,3 +97,127 @@ func (field Field) toSlice(values ...driver.Valuer) []interface{} {
 	}
 	return slice
 }
+
+// valueKind kind of column values for CheckTyping
+type valueKind uint8
+
+const (
+	kindAny valueKind = iota
+	kindString
+	kindNumber
+	kindBool
+	kindTime
+)
+
+func (k valueKind) String() string {
+	return [...]string{"any", "string", "number", "bool", "time"}[k]
+}
+
+// literalKind return kind of a literal value, values of unknown kind like Expr and driver.Valuer are kindAny
+func literalKind(value interface{}) valueKind {
+	switch value.(type) {
+	case nil, Expr, driver.Valuer:
+		return kindAny
+	case time.Time, *time.Time:
+		return kindTime
+	}
+
+	switch reflect.Indirect(reflect.ValueOf(value)).Kind() {
+	case reflect.String:
+		return kindString
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return kindNumber
+	case reflect.Bool:
+		return kindBool
+	default:
+		return kindAny
+	}
+}
+
+// valuerValue return value of valuer and its kind, an Expr or a valuer failing to return its value is kindAny
+func valuerValue(valuer driver.Valuer) (valueKind, driver.Value) {
+	if _, ok := valuer.(Expr); ok || valuer == nil {
+		return kindAny, nil
+	}
+	if rv := reflect.ValueOf(valuer); rv.Kind() == reflect.Ptr && rv.IsNil() {
+		return kindAny, nil
+	}
+	value, err := valuer.Value()
+	if err != nil {
+		return kindAny, nil
+	}
+	return literalKind(value), value
+}
+
+// numberValue return value of a number literal as float64
+func numberValue(value interface{}) (float64, bool) {
+	if literalKind(value) != kindNumber {
+		return 0, false
+	}
+	v := reflect.Indirect(reflect.ValueOf(value))
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), true
+	default:
+		return v.Float(), true
+	}
+}
+
+// compare return comparison e of field against value, which records a mismatch for CheckTyping
+// if the value of valuer is of another kind than the column
+func (field Field) compare(e clause.Expression, value driver.Valuer) Expr {
+	if field.kind != kindAny {
+		if kind, v := valuerValue(value); kind != kindAny && kind != field.kind {
+			return expr{e: mismatchedComparison{Expression: e, err: fmt.Errorf("compare %s column %s with %s value %v", field.kind, field.col.Name, kind, v)}}
+		}
+	}
+	return expr{e: e}
+}
+
+// mismatchedComparison comparison of a column against a literal of another kind, reported by CheckTyping
+type mismatchedComparison struct {
+	clause.Expression
+	err error
+}
+
+// CheckTyping return error if e compares a typed column against a literal of another type,
+// e.g. field.Field(stringField).Gt(5), typed fields like String and Int check arguments of their own methods at compile time
+func CheckTyping(e Expr) error {
+	return checkTyping(e.RawExpr())
+}
+
+func checkTyping(e interface{}) error {
+	var exprs []interface{}
+	switch e := e.(type) {
+	case mismatchedComparison:
+		return e.err
+	case Expr:
+		return checkTyping(e.RawExpr())
+	case clause.Expr:
+		exprs = e.Vars
+	case clause.AndConditions:
+		exprs = toInterfaces(e.Exprs)
+	case clause.OrConditions:
+		exprs = toInterfaces(e.Exprs)
+	case clause.NotConditions:
+		exprs = toInterfaces(e.Exprs)
+	}
+	for _, v := range exprs {
+		if err := checkTyping(v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func toInterfaces(exprs []clause.Expression) []interface{} {
+	values := make([]interface{}, len(exprs))
+	for i, e := range exprs {
+		values[i] = e
+	}
+	return values
+}
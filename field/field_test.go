@@ -42,6 +42,14 @@ func BuildToString(e Expr) (string, []interface{}) {
 	return stmt.SQL.String(), stmt.Vars
 }
 
+// WindowRawExpr return raw expression of a window function without the columns recorded for CheckWindowColumns
+func WindowRawExpr(e Expr) interface{} {
+	if w, ok := e.RawExpr().(checkedWindow); ok {
+		return w.Expr
+	}
+	return e.RawExpr()
+}
+
 type User struct {
 	gorm.Model
 	Name string
@@ -2,7 +2,9 @@ package field
 
 import (
 	"strings"
+	"sync"
 
+	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 )
 
@@ -34,3 +36,87 @@ func (f *function) Rand() String {
 func (f *function) Random() String {
 	return String{expr{e: clause.Expr{SQL: "RANDOM()"}}}
 }
+
+// NowUTC return current time normalized to UTC, equal to (now() AT TIME ZONE 'UTC')
+func (f *function) NowUTC() Time {
+	return Time{expr{e: clause.Expr{SQL: "(now() AT TIME ZONE 'UTC')"}}}
+}
+
+// StatementTimestamp return start time of the current statement, equal to statement_timestamp()
+func (f *function) StatementTimestamp() Time {
+	return Time{expr{e: clause.Expr{SQL: "statement_timestamp()"}}}
+}
+
+// ClockTimestamp return actual current time which changes even within a single statement, equal to clock_timestamp()
+func (f *function) ClockTimestamp() Time {
+	return Time{expr{e: clause.Expr{SQL: "clock_timestamp()"}}}
+}
+
+// ConversionRate return ratio of rows matching numeratorCond to rows matching denominatorCond,
+// equal to COUNT(*) FILTER (WHERE numeratorCond)::float / NULLIF(COUNT(*) FILTER (WHERE denominatorCond), 0)
+func (f *function) ConversionRate(numeratorCond, denominatorCond Expr) Float64 {
+	return Float64{expr{e: clause.Expr{
+		SQL:  "COUNT(*) FILTER (WHERE ?)::float / NULLIF(COUNT(*) FILTER (WHERE ?), 0)",
+		Vars: []interface{}{numeratorCond.RawExpr(), denominatorCond.RawExpr()},
+	}}}
+}
+
+// approxCountDistinctSQL native approximate distinct count by dialect name, ? is the column
+var approxCountDistinctSQL = newDialectSQL(map[string]string{
+	"oracle":    "APPROX_COUNT_DISTINCT(?)",
+	"sqlserver": "APPROX_COUNT_DISTINCT(?)",
+})
+
+// RegisterApproxCountDistinct set the native approximate distinct count of dialect used by ApproxCountDistinct,
+// ? is the column, an empty sql removes it. postgres needs an extension so it is not registered by default,
+// e.g. with postgresql-hll installed:
+//
+//	field.RegisterApproxCountDistinct("postgres", "hll_cardinality(hll_add_agg(hll_hash_any(?)))")
+func RegisterApproxCountDistinct(dialect, sql string) {
+	approxCountDistinctSQL.set(dialect, sql)
+}
+
+// ApproxCountDistinct return approximate count of distinct col, use the function registered by
+// RegisterApproxCountDistinct for the dialect of db, fall back to exact COUNT(DISTINCT col)
+func (f *function) ApproxCountDistinct(col Expr) Float64 {
+	return Float64{expr{e: approxCountDistinct{col: col.RawExpr()}}}
+}
+
+type approxCountDistinct struct{ col interface{} }
+
+func (a approxCountDistinct) Build(builder clause.Builder) {
+	sql := "COUNT(DISTINCT ?)"
+	if stmt, ok := builder.(*gorm.Statement); ok {
+		if native, ok := approxCountDistinctSQL.get(stmt.Dialector.Name()); ok {
+			sql = native
+		}
+	}
+	clause.Expr{SQL: sql, Vars: []interface{}{a.col}}.Build(builder)
+}
+
+// dialectSQL SQL of a function by dialect name, it is safe for concurrent use
+type dialectSQL struct {
+	mu  sync.RWMutex
+	sql map[string]string
+}
+
+func newDialectSQL(sql map[string]string) *dialectSQL {
+	return &dialectSQL{sql: sql}
+}
+
+func (d *dialectSQL) get(dialect string) (string, bool) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	sql, ok := d.sql[dialect]
+	return sql, ok
+}
+
+func (d *dialectSQL) set(dialect, sql string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if sql == "" {
+		delete(d.sql, dialect)
+		return
+	}
+	d.sql[dialect] = sql
+}
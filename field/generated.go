@@ -0,0 +1,29 @@
+package field
+
+import (
+	"database/sql/driver"
+	"fmt"
+)
+
+// Generated generated (computed) column, it can be selected and filtered but not assigned,
+// assignments fail by CondError since the database rejects writes to generated columns
+type Generated struct{ Field }
+
+// Value fail by CondError, generated column can not be assigned
+func (field Generated) Value(driver.Valuer) AssignExpr { return field.invalidAssign() }
+
+// Null fail by CondError, generated column can not be assigned
+func (field Generated) Null() AssignExpr { return field.invalidAssign() }
+
+// SetCol fail by CondError, generated column can not be assigned
+func (field Generated) SetCol(Expr) AssignExpr { return field.invalidAssign() }
+
+// JsonbSet fail by CondError, generated column can not be assigned
+func (field Generated) JsonbSet([]string, interface{}) AssignExpr { return field.invalidAssign() }
+
+// JsonbInsert fail by CondError, generated column can not be assigned
+func (field Generated) JsonbInsert([]string, interface{}) AssignExpr { return field.invalidAssign() }
+
+func (field Generated) invalidAssign() AssignExpr {
+	return invalidExpr{expr: field.expr, err: fmt.Errorf("generated column %s can not be assigned", field.col.Name)}
+}
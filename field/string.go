@@ -94,6 +94,16 @@ func (field String) IfNull(value string) Expr {
 	return field.ifNull(value)
 }
 
+// EmptyToNull treat empty string as NULL, equal to NULLIF(self, "")
+func (field String) EmptyToNull() String {
+	return String{expr{e: clause.Expr{SQL: "NULLIF(?, '')", Vars: []interface{}{field.RawExpr()}}}}
+}
+
+// Coalesce equal to COALESCE(self, values...), a value can be a string or another column
+func (field String) Coalesce(values ...interface{}) String {
+	return String{expr{e: field.coalesce(values)}}
+}
+
 // FindInSet equal to FIND_IN_SET(field_name, input_string_list)
 func (field String) FindInSet(targetList string) Expr {
 	return expr{e: clause.Expr{SQL: "FIND_IN_SET(?,?)", Vars: []interface{}{field.RawExpr(), targetList}}}
@@ -133,6 +143,17 @@ func (field String) Upper() String {
 	return String{expr{e: clause.Expr{SQL: "UPPER(?)", Vars: []interface{}{field.RawExpr()}}}}
 }
 
+// MD5 equal to md5(self), return hex of the md5 hash
+func (field String) MD5() String {
+	return String{expr{e: clause.Expr{SQL: "md5(?)", Vars: []interface{}{field.RawExpr()}}}}
+}
+
+// SHA256Hex equal to encode(sha256(self::bytea), 'hex'), return hex of the sha256 hash.
+// sha256 is built in since PostgreSQL 11, older versions require the pgcrypto extension
+func (field String) SHA256Hex() String {
+	return String{expr{e: clause.Expr{SQL: "encode(sha256(?::bytea), 'hex')", Vars: []interface{}{field.RawExpr()}}}}
+}
+
 // Field ...
 func (field String) Field(values ...string) String {
 	return String{field.field(values)}
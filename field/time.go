@@ -70,6 +70,17 @@ func (field Time) Sub(value time.Duration) Time {
 	return Time{field.sub(value)}
 }
 
+// AddInterval add a postgres interval such as "1 day", equal to self + ?::interval,
+// the interval is bound as var, the cast takes the place of INTERVAL which only accepts literals
+func (field Time) AddInterval(interval string) Time {
+	return Time{field.setE(clause.Expr{SQL: "? + ?::interval", Vars: []interface{}{field.RawExpr(), interval}})}
+}
+
+// SubInterval subtract a postgres interval such as "2 hours", equal to self - ?::interval
+func (field Time) SubInterval(interval string) Time {
+	return Time{field.setE(clause.Expr{SQL: "? - ?::interval", Vars: []interface{}{field.RawExpr(), interval}})}
+}
+
 // Date convert to data, equal to "DATE(time_expr)"
 func (field Time) Date() Time {
 	return Time{expr{e: clause.Expr{SQL: "DATE(?)", Vars: []interface{}{field.RawExpr()}}}}
@@ -85,6 +96,11 @@ func (field Time) DateFormat(value string) String {
 	return String{expr{e: clause.Expr{SQL: "DATE_FORMAT(?,?)", Vars: []interface{}{field.RawExpr(), value}}}}
 }
 
+// DateTrunc equal to DATE_TRUNC(unit, self)
+func (field Time) DateTrunc(unit string) Time {
+	return field.expr.DateTrunc(unit)
+}
+
 // Now return result of NOW()
 func (field Time) Now() Time {
 	return Time{expr{e: clause.Expr{SQL: "NOW()"}}}
@@ -196,3 +212,19 @@ func (field Time) toSlice(values ...time.Time) []interface{} {
 	}
 	return slice
 }
+
+// RangeOverlaps whether time range of columns [startCol, endCol) overlaps [start, end),
+// equal to tstzrange(startCol, endCol) && tstzrange(start, end),
+// bounds such as "[]" or "(]" specify inclusive/exclusive bounds of both ranges instead of the default "[)"
+func RangeOverlaps(startCol, endCol Time, start, end time.Time, bounds ...string) Expr {
+	if len(bounds) > 0 && bounds[0] != "" {
+		return expr{e: clause.Expr{
+			SQL:  "tstzrange(?, ?, ?) && tstzrange(?, ?, ?)",
+			Vars: []interface{}{startCol.RawExpr(), endCol.RawExpr(), bounds[0], start, end, bounds[0]},
+		}}
+	}
+	return expr{e: clause.Expr{
+		SQL:  "tstzrange(?, ?) && tstzrange(?, ?)",
+		Vars: []interface{}{startCol.RawExpr(), endCol.RawExpr(), start, end},
+	}}
+}
@@ -0,0 +1,278 @@
+package field
+
+import (
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// FrameType frame unit of a window frame
+type FrameType string
+
+const (
+	// FrameRows ROWS frame
+	FrameRows FrameType = "ROWS"
+	// FrameRange RANGE frame
+	FrameRange FrameType = "RANGE"
+	// FrameGroups GROUPS frame, offsets count peer groups of ORDER BY
+	FrameGroups FrameType = "GROUPS"
+)
+
+// FrameBoundType type of a window frame bound
+type FrameBoundType string
+
+const (
+	// UnboundedPreceding UNBOUNDED PRECEDING
+	UnboundedPreceding FrameBoundType = "UNBOUNDED PRECEDING"
+	// Preceding offset PRECEDING
+	Preceding FrameBoundType = "PRECEDING"
+	// CurrentRow CURRENT ROW
+	CurrentRow FrameBoundType = "CURRENT ROW"
+	// Following offset FOLLOWING
+	Following FrameBoundType = "FOLLOWING"
+	// UnboundedFollowing UNBOUNDED FOLLOWING
+	UnboundedFollowing FrameBoundType = "UNBOUNDED FOLLOWING"
+)
+
+// FrameBound a bound of window frame, Offset is only used by Preceding and Following
+type FrameBound struct {
+	Type   FrameBoundType
+	Offset interface{}
+}
+
+// FrameExclude rows excluded from a window frame
+type FrameExclude string
+
+const (
+	// ExcludeCurrentRow EXCLUDE CURRENT ROW
+	ExcludeCurrentRow FrameExclude = "EXCLUDE CURRENT ROW"
+	// ExcludeGroup EXCLUDE GROUP, the current row and its peers
+	ExcludeGroup FrameExclude = "EXCLUDE GROUP"
+	// ExcludeTies EXCLUDE TIES, peers of the current row but not itself
+	ExcludeTies FrameExclude = "EXCLUDE TIES"
+	// ExcludeNoOthers EXCLUDE NO OTHERS
+	ExcludeNoOthers FrameExclude = "EXCLUDE NO OTHERS"
+)
+
+// FrameSpec window frame, e.g. ROWS BETWEEN 1 PRECEDING AND CURRENT ROW
+// the frame has only a start bound when End.Type is empty, Exclude is omitted when empty
+type FrameSpec struct {
+	Type    FrameType
+	Start   FrameBound
+	End     FrameBound
+	Exclude FrameExclude
+}
+
+// WindowSpec window specification in OVER (...)
+type WindowSpec struct {
+	PartitionBy []Expr
+	OrderBy     []Expr
+	Frame       *FrameSpec
+}
+
+// WindowFunction function which is evaluated over a window, use Over to specify the window
+type WindowFunction struct {
+	fn       clause.Expr
+	fromLast bool
+	nulls    string
+	filter   Expr
+
+	// defaults parts of the spec used when Over is called with a spec without them, see With
+	defaults WindowSpec
+}
+
+func newWindowFunction(sql string, vars ...interface{}) WindowFunction {
+	return WindowFunction{fn: clause.Expr{SQL: sql, Vars: vars}}
+}
+
+// FromLast count rows from the last row of the frame, equal to fn FROM LAST
+func (w WindowFunction) FromLast() WindowFunction {
+	w.fromLast = true
+	return w
+}
+
+// IgnoreNulls skip NULL values, equal to fn IGNORE NULLS
+func (w WindowFunction) IgnoreNulls() WindowFunction {
+	w.nulls = "IGNORE NULLS"
+	return w
+}
+
+// RespectNulls keep NULL values, equal to fn RESPECT NULLS, which is the default of databases
+func (w WindowFunction) RespectNulls() WindowFunction {
+	w.nulls = "RESPECT NULLS"
+	return w
+}
+
+// Filter aggregate only rows matching cond, equal to fn FILTER (WHERE cond)
+func (w WindowFunction) Filter(cond Expr) WindowFunction {
+	w.filter = cond
+	return w
+}
+
+// With set defaults of the window spec, each part of defaults is used by Over only if its spec does not set it,
+// e.g. functions of a report sharing one partition. Defaults do not apply to OverNamed
+func (w WindowFunction) With(defaults WindowSpec) WindowFunction {
+	w.defaults = defaults.withDefaults(w.defaults)
+	return w
+}
+
+// withDefaults return spec with its unset parts taken from defaults
+func (spec WindowSpec) withDefaults(defaults WindowSpec) WindowSpec {
+	if len(spec.PartitionBy) == 0 {
+		spec.PartitionBy = defaults.PartitionBy
+	}
+	if len(spec.OrderBy) == 0 {
+		spec.OrderBy = defaults.OrderBy
+	}
+	if spec.Frame == nil {
+		spec.Frame = defaults.Frame
+	}
+	return spec
+}
+
+// Over evaluate the function over window spec, equal to fn OVER (spec), unset parts of spec are taken from With
+func (w WindowFunction) Over(spec WindowSpec) Expr {
+	sql, vars := w.build()
+	spec = spec.withDefaults(w.defaults)
+	windowSQL, windowVars := buildWindowExpression(spec)
+	over := clause.Expr{SQL: sql + " OVER (" + windowSQL + ")", Vars: append(vars, windowVars...)}
+	return Field{expr{e: checkedWindow{Expr: over, columns: windowColumns(spec)}}}
+}
+
+// checkedWindow window function which records its PARTITION BY and ORDER BY columns for CheckWindowColumns
+type checkedWindow struct {
+	clause.Expr
+	columns []clause.Column
+}
+
+// CheckWindowColumns return error if a window function of e is partitioned or ordered by a column
+// which is not a field of s, columns qualified with other tables are skipped
+func CheckWindowColumns(e Expr, s *schema.Schema) error {
+	if s == nil {
+		return nil
+	}
+	return checkWindowColumns(e.RawExpr(), s)
+}
+
+func checkWindowColumns(e interface{}, s *schema.Schema) error {
+	switch e := e.(type) {
+	case checkedWindow:
+		for _, col := range e.columns {
+			if col.Table != "" && col.Table != s.Table {
+				continue
+			}
+			if s.LookUpField(col.Name) == nil {
+				return fmt.Errorf("unknown window column %q of table %s", col.Name, s.Table)
+			}
+		}
+		return checkWindowColumns(e.Expr, s)
+	case clause.Expr:
+		for _, v := range e.Vars {
+			if err := checkWindowColumns(v, s); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// windowColumns columns of PARTITION BY and ORDER BY, including the ones sorted by Asc or Desc
+func windowColumns(spec WindowSpec) []clause.Column {
+	var columns []clause.Column
+	for _, e := range append(append([]Expr{}, spec.PartitionBy...), spec.OrderBy...) {
+		raw := e.RawExpr()
+		if sorted, ok := raw.(clause.Expr); ok && strings.HasPrefix(sorted.SQL, "? ") && len(sorted.Vars) == 1 {
+			raw = sorted.Vars[0]
+		}
+		if col, ok := raw.(clause.Column); ok {
+			columns = append(columns, col)
+		}
+	}
+	return columns
+}
+
+// OverNamed evaluate the function over the window named name, equal to fn OVER name,
+// the window is defined by a WINDOW clause of the query, see NamedWindow
+func (w WindowFunction) OverNamed(name string) Expr {
+	sql, vars := w.build()
+	return Field{expr{e: clause.Expr{SQL: sql + " OVER ?", Vars: append(vars, clause.Column{Name: name})}}}
+}
+
+// build return the function with its modifiers before OVER
+func (w WindowFunction) build() (string, []interface{}) {
+	sql, vars := w.fn.SQL, append([]interface{}{}, w.fn.Vars...)
+	if w.fromLast {
+		sql += " FROM LAST"
+	}
+	if w.nulls != "" {
+		sql += " " + w.nulls
+	}
+	if w.filter != nil {
+		sql += " FILTER (WHERE ?)"
+		vars = append(vars, w.filter.RawExpr())
+	}
+	return sql, vars
+}
+
+// NamedWindow window definition of WINDOW clause, equal to name AS (spec)
+func NamedWindow(name string, spec WindowSpec) Expr {
+	windowSQL, windowVars := buildWindowExpression(spec)
+	return Field{expr{e: clause.Expr{SQL: "? AS (" + windowSQL + ")", Vars: append([]interface{}{clause.Column{Name: name}}, windowVars...)}}}
+}
+
+// buildWindowExpression build window spec into sql with placeholders, vars are in positional order
+func buildWindowExpression(spec WindowSpec) (string, []interface{}) {
+	var (
+		parts []string
+		vars  []interface{}
+	)
+	if len(spec.PartitionBy) > 0 {
+		parts = append(parts, "PARTITION BY "+placeholders(len(spec.PartitionBy)))
+		for _, e := range spec.PartitionBy {
+			vars = append(vars, e.RawExpr())
+		}
+	}
+	if len(spec.OrderBy) > 0 {
+		parts = append(parts, "ORDER BY "+placeholders(len(spec.OrderBy)))
+		for _, e := range spec.OrderBy {
+			vars = append(vars, e.RawExpr())
+		}
+	}
+	if spec.Frame != nil {
+		frameSQL, frameVars := buildFrameClause(spec.Frame)
+		parts = append(parts, frameSQL)
+		vars = append(vars, frameVars...)
+	}
+	return strings.Join(parts, " "), vars
+}
+
+// buildFrameClause build frame bound by bound, return offsets of bounds as vars
+func buildFrameClause(frame *FrameSpec) (string, []interface{}) {
+	var vars []interface{}
+	buildBound := func(bound FrameBound) string {
+		switch bound.Type {
+		case Preceding, Following:
+			vars = append(vars, bound.Offset)
+			return "? " + string(bound.Type)
+		default:
+			return string(bound.Type)
+		}
+	}
+
+	sql := string(frame.Type) + " "
+	if frame.End.Type == "" {
+		sql += buildBound(frame.Start)
+	} else {
+		sql += "BETWEEN " + buildBound(frame.Start) + " AND " + buildBound(frame.End)
+	}
+	if frame.Exclude != "" {
+		sql += " " + string(frame.Exclude)
+	}
+	return sql, vars
+}
+
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?,", n), ",")
+}
@@ -0,0 +1,144 @@
+package field
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// FirstValue equal to FIRST_VALUE(self)
+func (e expr) FirstValue() WindowFunction {
+	return newWindowFunction("FIRST_VALUE(?)", e.RawExpr())
+}
+
+// LastValue equal to LAST_VALUE(self), the frame defaults to the whole partition
+// (ROWS BETWEEN UNBOUNDED PRECEDING AND UNBOUNDED FOLLOWING) instead of ending at the current row
+func (e expr) LastValue() WindowFunction {
+	return newWindowFunction("LAST_VALUE(?)", e.RawExpr()).With(WindowSpec{
+		Frame: &FrameSpec{Type: FrameRows, Start: FrameBound{Type: UnboundedPreceding}, End: FrameBound{Type: UnboundedFollowing}},
+	})
+}
+
+// NthValue equal to NTH_VALUE(self, n)
+func (e expr) NthValue(n int) WindowFunction {
+	return newWindowFunction("NTH_VALUE(?, ?)", e.RawExpr(), n)
+}
+
+// NthValueFromLast equal to NTH_VALUE(self, n) FROM LAST
+func (e expr) NthValueFromLast(n int) WindowFunction {
+	return e.NthValue(n).FromLast()
+}
+
+// WindowSum equal to SUM(self) evaluated over a window
+func (e expr) WindowSum() WindowFunction {
+	return newWindowFunction("SUM(?)", e.RawExpr())
+}
+
+// WindowCount equal to COUNT(self) evaluated over a window
+func (e expr) WindowCount() WindowFunction {
+	return newWindowFunction("COUNT(?)", e.RawExpr())
+}
+
+// Lag equal to LAG(self, offset), value of the row offset rows before the current row
+func (e expr) Lag(offset int) WindowFunction {
+	return newWindowFunction("LAG(?, ?)", e.RawExpr(), offset)
+}
+
+// Lead equal to LEAD(self, offset), value of the row offset rows after the current row
+func (e expr) Lead(offset int) WindowFunction {
+	return newWindowFunction("LEAD(?, ?)", e.RawExpr(), offset)
+}
+
+// PeriodOverPeriod return change of self against the value periods rows before in window spec,
+// abs equal to self - LAG(self, periods) OVER (spec),
+// pct equal to (self - LAG(self, periods) OVER (spec)) * 100.0 / NULLIF(LAG(self, periods) OVER (spec), 0)
+func (e expr) PeriodOverPeriod(periods int, spec WindowSpec) (abs Expr, pct Expr) {
+	previous := e.Lag(periods).Over(spec).(Field)
+	abs = e.SubCol(previous)
+	pct = expr{e: clause.Expr{
+		SQL:  "? * 100.0 / ?",
+		Vars: []interface{}{parenthesize(abs.RawExpr()), previous.NullIf(0).RawExpr()},
+	}}
+	return abs, pct
+}
+
+// MovingAggOverTime return agg(self) over rows of the last days days up to the current row,
+// equal to agg(self) OVER (... ORDER BY ts RANGE BETWEEN INTERVAL 'N days' PRECEDING AND CURRENT ROW),
+// agg receives self as a Field, spec must be ordered by a single Time column, or it fails by CondError,
+// the frame of spec is replaced
+func (e expr) MovingAggOverTime(agg func(Expr) Expr, days int, spec WindowSpec) Expr {
+	if len(spec.OrderBy) != 1 {
+		return invalidExpr{expr: e, err: fmt.Errorf("moving aggregate over time of %s must be ordered by a single time column, got %d", e.col.Name, len(spec.OrderBy))}
+	}
+	if _, ok := spec.OrderBy[0].(Time); !ok {
+		return invalidExpr{expr: e, err: fmt.Errorf("moving aggregate over time of %s must be ordered by a time column, got %T", e.col.Name, spec.OrderBy[0])}
+	}
+
+	spec.Frame = &FrameSpec{
+		Type:  FrameRange,
+		Start: FrameBound{Type: Preceding, Offset: clause.Expr{SQL: fmt.Sprintf("INTERVAL '%d days'", days)}},
+		End:   FrameBound{Type: CurrentRow},
+	}
+	return newWindowFunction("?", agg(Field{e}).RawExpr()).Over(spec)
+}
+
+// SessionId number rows into sessions, a new session starts when self is more than gapThreshold after the previous row,
+// equal to SUM(CASE WHEN self - LAG(self) OVER (spec) > INTERVAL 'gap' THEN 1 ELSE 0 END) OVER (spec ROWS UNBOUNDED PRECEDING ...),
+// spec should be ordered by self, its frame is replaced and the first session of each partition is 0
+func (e expr) SessionId(gapThreshold time.Duration, spec WindowSpec) Expr {
+	previous := e.Lag(1).Over(WindowSpec{PartitionBy: spec.PartitionBy, OrderBy: spec.OrderBy})
+	newSession := expr{e: clause.Expr{
+		SQL: "CASE WHEN ? - ? > ? THEN 1 ELSE 0 END",
+		Vars: []interface{}{
+			e.RawExpr(), previous.RawExpr(),
+			clause.Expr{SQL: fmt.Sprintf("INTERVAL '%d microseconds'", gapThreshold.Microseconds())},
+		},
+	}}
+
+	spec.Frame = &FrameSpec{Type: FrameRows, Start: FrameBound{Type: UnboundedPreceding}, End: FrameBound{Type: CurrentRow}}
+	return newSession.WindowSum().Over(spec)
+}
+
+// runningApproxDistinctSQL cumulative approximate distinct count by dialect name, the first ? is the column
+// and the second one is the window spec. the postgres one needs the postgresql-hll extension
+var runningApproxDistinctSQL = newDialectSQL(map[string]string{
+	"postgres": "hll_cardinality(hll_add_agg(hll_hash_any(?)) OVER (?))",
+})
+
+// RegisterRunningApproxDistinct set the cumulative approximate distinct count of dialect used by
+// RunningApproxDistinct, the first ? is the column and the second one is the window spec, an empty sql removes it
+func RegisterRunningApproxDistinct(dialect, sql string) {
+	runningApproxDistinctSQL.set(dialect, sql)
+}
+
+// RunningApproxDistinct return approximate count of distinct self up to the current row of window spec,
+// e.g. running unique users, it uses the function registered by RegisterRunningApproxDistinct for the dialect of db.
+// there is no exact fall back as COUNT(DISTINCT) is not allowed over a window, other dialects fail the statement
+func (e expr) RunningApproxDistinct(spec WindowSpec) Int {
+	windowSQL, windowVars := buildWindowExpression(spec)
+	return Int{expr{e: runningApproxDistinct{col: e.RawExpr(), window: clause.Expr{SQL: windowSQL, Vars: windowVars}}}}
+}
+
+type runningApproxDistinct struct {
+	col    interface{}
+	window clause.Expr
+}
+
+func (r runningApproxDistinct) Build(builder clause.Builder) {
+	var dialect string
+	stmt, isStmt := builder.(*gorm.Statement)
+	if isStmt {
+		dialect = stmt.Dialector.Name()
+	}
+	native, ok := runningApproxDistinctSQL.get(dialect)
+	if !ok {
+		if isStmt {
+			_ = stmt.AddError(fmt.Errorf("running approximate distinct count is not supported by dialect %s", dialect))
+		}
+		builder.WriteString("NULL")
+		return
+	}
+	clause.Expr{SQL: native, Vars: []interface{}{r.col, r.window}}.Build(builder)
+}
@@ -0,0 +1,291 @@
+package field_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"gorm.io/gen/field"
+)
+
+func TestWindowFunction_Build(t *testing.T) {
+	var (
+		id    = field.NewInt("", "id")
+		dept  = field.NewString("", "dept")
+		ts    = field.NewTime("", "ts")
+		score = field.NewFloat64("", "score")
+	)
+	abs, pct := score.PeriodOverPeriod(1, field.WindowSpec{PartitionBy: []field.Expr{dept}, OrderBy: []field.Expr{id}})
+
+	testcases := []struct {
+		Expr         field.Expr
+		ExpectedVars []interface{}
+		Result       string
+	}{
+		{
+			Expr:   score.FirstValue().Over(field.WindowSpec{PartitionBy: []field.Expr{dept}, OrderBy: []field.Expr{id}}),
+			Result: "FIRST_VALUE(`score`) OVER (PARTITION BY `dept` ORDER BY `id`)",
+		},
+		{
+			Expr:         score.NthValue(2).Over(field.WindowSpec{OrderBy: []field.Expr{score.Desc()}}),
+			ExpectedVars: []interface{}{2},
+			Result:       "NTH_VALUE(`score`, ?) OVER (ORDER BY `score` DESC)",
+		},
+		{
+			Expr:   score.FirstValue().Over(field.WindowSpec{OrderBy: []field.Expr{dept.Asc(), id.Desc()}}),
+			Result: "FIRST_VALUE(`score`) OVER (ORDER BY `dept` ASC,`id` DESC)",
+		},
+		{
+			Expr:         score.NthValueFromLast(2).Over(field.WindowSpec{PartitionBy: []field.Expr{dept}}),
+			ExpectedVars: []interface{}{2},
+			Result:       "NTH_VALUE(`score`, ?) FROM LAST OVER (PARTITION BY `dept`)",
+		},
+		{
+			Expr:         score.NthValueFromLast(3).IgnoreNulls().Over(field.WindowSpec{OrderBy: []field.Expr{id}}),
+			ExpectedVars: []interface{}{3},
+			Result:       "NTH_VALUE(`score`, ?) FROM LAST IGNORE NULLS OVER (ORDER BY `id`)",
+		},
+		{
+			Expr: score.LastValue().Over(field.WindowSpec{
+				OrderBy: []field.Expr{id},
+				Frame:   &field.FrameSpec{Type: field.FrameRows, Start: field.FrameBound{Type: field.UnboundedPreceding}, End: field.FrameBound{Type: field.UnboundedFollowing}},
+			}),
+			Result: "LAST_VALUE(`score`) OVER (ORDER BY `id` ROWS BETWEEN UNBOUNDED PRECEDING AND UNBOUNDED FOLLOWING)",
+		},
+		{
+			Expr:   score.LastValue().Over(field.WindowSpec{PartitionBy: []field.Expr{dept}, OrderBy: []field.Expr{id}}),
+			Result: "LAST_VALUE(`score`) OVER (PARTITION BY `dept` ORDER BY `id` ROWS BETWEEN UNBOUNDED PRECEDING AND UNBOUNDED FOLLOWING)",
+		},
+		{
+			Expr: score.LastValue().Over(field.WindowSpec{
+				OrderBy: []field.Expr{id},
+				Frame:   &field.FrameSpec{Type: field.FrameRows, Start: field.FrameBound{Type: field.CurrentRow}},
+			}),
+			Result: "LAST_VALUE(`score`) OVER (ORDER BY `id` ROWS CURRENT ROW)",
+		},
+		{
+			Expr: score.FirstValue().Over(field.WindowSpec{
+				OrderBy: []field.Expr{id},
+				Frame:   &field.FrameSpec{Type: field.FrameRows, Start: field.FrameBound{Type: field.Preceding, Offset: 1}, End: field.FrameBound{Type: field.Following, Offset: 1}},
+			}),
+			ExpectedVars: []interface{}{1, 1},
+			Result:       "FIRST_VALUE(`score`) OVER (ORDER BY `id` ROWS BETWEEN ? PRECEDING AND ? FOLLOWING)",
+		},
+		{
+			Expr: score.FirstValue().Over(field.WindowSpec{
+				OrderBy: []field.Expr{id},
+				Frame:   &field.FrameSpec{Type: field.FrameGroups, Start: field.FrameBound{Type: field.Preceding, Offset: 1}, End: field.FrameBound{Type: field.Following, Offset: 1}},
+			}),
+			ExpectedVars: []interface{}{1, 1},
+			Result:       "FIRST_VALUE(`score`) OVER (ORDER BY `id` GROUPS BETWEEN ? PRECEDING AND ? FOLLOWING)",
+		},
+		{
+			Expr: score.WindowSum().Over(field.WindowSpec{
+				OrderBy: []field.Expr{id},
+				Frame:   &field.FrameSpec{Type: field.FrameRows, Start: field.FrameBound{Type: field.Preceding, Offset: 2}, End: field.FrameBound{Type: field.Following, Offset: 2}, Exclude: field.ExcludeCurrentRow},
+			}),
+			ExpectedVars: []interface{}{2, 2},
+			Result:       "SUM(`score`) OVER (ORDER BY `id` ROWS BETWEEN ? PRECEDING AND ? FOLLOWING EXCLUDE CURRENT ROW)",
+		},
+		{
+			Expr: score.WindowSum().Over(field.WindowSpec{
+				OrderBy: []field.Expr{id},
+				Frame:   &field.FrameSpec{Type: field.FrameRows, Start: field.FrameBound{Type: field.Preceding, Offset: 2}, End: field.FrameBound{Type: field.Following, Offset: 2}, Exclude: field.ExcludeGroup},
+			}),
+			ExpectedVars: []interface{}{2, 2},
+			Result:       "SUM(`score`) OVER (ORDER BY `id` ROWS BETWEEN ? PRECEDING AND ? FOLLOWING EXCLUDE GROUP)",
+		},
+		{
+			Expr: score.WindowSum().Over(field.WindowSpec{
+				OrderBy: []field.Expr{id},
+				Frame:   &field.FrameSpec{Type: field.FrameRows, Start: field.FrameBound{Type: field.Preceding, Offset: 2}, End: field.FrameBound{Type: field.Following, Offset: 2}, Exclude: field.ExcludeTies},
+			}),
+			ExpectedVars: []interface{}{2, 2},
+			Result:       "SUM(`score`) OVER (ORDER BY `id` ROWS BETWEEN ? PRECEDING AND ? FOLLOWING EXCLUDE TIES)",
+		},
+		{
+			Expr: score.WindowSum().Over(field.WindowSpec{
+				OrderBy: []field.Expr{id},
+				Frame:   &field.FrameSpec{Type: field.FrameRows, Start: field.FrameBound{Type: field.Preceding, Offset: 2}, End: field.FrameBound{Type: field.Following, Offset: 2}, Exclude: field.ExcludeNoOthers},
+			}),
+			ExpectedVars: []interface{}{2, 2},
+			Result:       "SUM(`score`) OVER (ORDER BY `id` ROWS BETWEEN ? PRECEDING AND ? FOLLOWING EXCLUDE NO OTHERS)",
+		},
+		{
+			Expr: score.FirstValue().Over(field.WindowSpec{
+				OrderBy: []field.Expr{id},
+				Frame:   &field.FrameSpec{Type: field.FrameRange, Start: field.FrameBound{Type: field.CurrentRow}},
+			}),
+			Result: "FIRST_VALUE(`score`) OVER (ORDER BY `id` RANGE CURRENT ROW)",
+		},
+		{
+			Expr:         score.Lag(1).Over(field.WindowSpec{OrderBy: []field.Expr{id}}),
+			ExpectedVars: []interface{}{1},
+			Result:       "LAG(`score`, ?) OVER (ORDER BY `id`)",
+		},
+		{
+			Expr:         score.Lead(2).Over(field.WindowSpec{OrderBy: []field.Expr{id}}),
+			ExpectedVars: []interface{}{2},
+			Result:       "LEAD(`score`, ?) OVER (ORDER BY `id`)",
+		},
+		{
+			Expr: score.MovingAggOverTime(func(e field.Expr) field.Expr { return e.(field.Field).Sum() }, 7,
+				field.WindowSpec{PartitionBy: []field.Expr{dept}, OrderBy: []field.Expr{ts}}),
+			Result: "SUM(`score`) OVER (PARTITION BY `dept` ORDER BY `ts` RANGE BETWEEN INTERVAL '7 days' PRECEDING AND CURRENT ROW)",
+		},
+		{
+			Expr:         ts.SessionId(30*time.Minute, field.WindowSpec{PartitionBy: []field.Expr{id}, OrderBy: []field.Expr{ts}}),
+			ExpectedVars: []interface{}{1},
+			Result: "SUM(CASE WHEN `ts` - LAG(`ts`, ?) OVER (PARTITION BY `id` ORDER BY `ts`) > INTERVAL '1800000000 microseconds' THEN 1 ELSE 0 END) " +
+				"OVER (PARTITION BY `id` ORDER BY `ts` ROWS BETWEEN UNBOUNDED PRECEDING AND CURRENT ROW)",
+		},
+		{
+			Expr: field.NewFloat64("", "amount").WindowSum().Filter(field.NewString("", "status").Eq("paid")).Over(field.WindowSpec{
+				PartitionBy: []field.Expr{dept},
+				OrderBy:     []field.Expr{id},
+				Frame:       &field.FrameSpec{Type: field.FrameRows, Start: field.FrameBound{Type: field.Preceding, Offset: 3}, End: field.FrameBound{Type: field.CurrentRow}},
+			}),
+			ExpectedVars: []interface{}{"paid", 3},
+			Result:       "SUM(`amount`) FILTER (WHERE `status` = ?) OVER (PARTITION BY `dept` ORDER BY `id` ROWS BETWEEN ? PRECEDING AND CURRENT ROW)",
+		},
+		{
+			Expr:         id.WindowCount().Filter(field.NewString("", "status").Eq("active")).Over(field.WindowSpec{PartitionBy: []field.Expr{dept}}),
+			ExpectedVars: []interface{}{"active"},
+			Result:       "COUNT(`id`) FILTER (WHERE `status` = ?) OVER (PARTITION BY `dept`)",
+		},
+		{
+			Expr:         score.WindowSum().Filter(dept.Eq("a")).OverNamed("w"),
+			ExpectedVars: []interface{}{"a"},
+			Result:       "SUM(`score`) FILTER (WHERE `dept` = ?) OVER `w`",
+		},
+		{
+			Expr:         field.NamedWindow("w", field.WindowSpec{PartitionBy: []field.Expr{dept}, OrderBy: []field.Expr{id}, Frame: &field.FrameSpec{Type: field.FrameRows, Start: field.FrameBound{Type: field.Preceding, Offset: 2}}}),
+			ExpectedVars: []interface{}{2},
+			Result:       "`w` AS (PARTITION BY `dept` ORDER BY `id` ROWS ? PRECEDING)",
+		},
+		{
+			Expr:         score.Lag(1).IgnoreNulls().Over(field.WindowSpec{PartitionBy: []field.Expr{dept}, OrderBy: []field.Expr{id}}),
+			ExpectedVars: []interface{}{1},
+			Result:       "LAG(`score`, ?) IGNORE NULLS OVER (PARTITION BY `dept` ORDER BY `id`)",
+		},
+		{
+			Expr:         score.Lead(2).RespectNulls().Over(field.WindowSpec{OrderBy: []field.Expr{id}}),
+			ExpectedVars: []interface{}{2},
+			Result:       "LEAD(`score`, ?) RESPECT NULLS OVER (ORDER BY `id`)",
+		},
+		{
+			Expr:         score.NthValue(2).FromLast().RespectNulls().IgnoreNulls().Over(field.WindowSpec{OrderBy: []field.Expr{id}}),
+			ExpectedVars: []interface{}{2},
+			Result:       "NTH_VALUE(`score`, ?) FROM LAST IGNORE NULLS OVER (ORDER BY `id`)",
+		},
+		{
+			Expr:         abs,
+			ExpectedVars: []interface{}{1},
+			Result:       "`score` - LAG(`score`, ?) OVER (PARTITION BY `dept` ORDER BY `id`)",
+		},
+		{
+			Expr:         pct,
+			ExpectedVars: []interface{}{1, 1, 0},
+			Result:       "(`score` - LAG(`score`, ?) OVER (PARTITION BY `dept` ORDER BY `id`)) * 100.0 / NULLIF(LAG(`score`, ?) OVER (PARTITION BY `dept` ORDER BY `id`), ?)",
+		},
+	}
+
+	for _, testcase := range testcases {
+		field.CheckBuildExpr(t, testcase.Expr, testcase.Result, testcase.ExpectedVars)
+	}
+}
+
+func TestWindowFunction_With(t *testing.T) {
+	var (
+		id     = field.NewInt("", "id")
+		dept   = field.NewString("", "dept")
+		region = field.NewString("", "region")
+		score  = field.NewFloat64("", "score")
+	)
+	defaults := field.WindowSpec{PartitionBy: []field.Expr{dept}}
+
+	field.CheckBuildExpr(t, score.WindowSum().With(defaults).Over(field.WindowSpec{OrderBy: []field.Expr{id}}),
+		"SUM(`score`) OVER (PARTITION BY `dept` ORDER BY `id`)", nil)
+	field.CheckBuildExpr(t, score.WindowSum().With(defaults).Over(field.WindowSpec{PartitionBy: []field.Expr{region}, OrderBy: []field.Expr{id}}),
+		"SUM(`score`) OVER (PARTITION BY `region` ORDER BY `id`)", nil)
+	field.CheckBuildExpr(t, score.LastValue().With(defaults).Over(field.WindowSpec{OrderBy: []field.Expr{id}}),
+		"LAST_VALUE(`score`) OVER (PARTITION BY `dept` ORDER BY `id` ROWS BETWEEN UNBOUNDED PRECEDING AND UNBOUNDED FOLLOWING)", nil)
+	field.CheckBuildExpr(t, score.WindowSum().With(defaults).OverNamed("w"), "SUM(`score`) OVER `w`", nil)
+}
+
+func TestWindowFunction_frameOffsetVars(t *testing.T) {
+	score, id := field.NewFloat64("", "score"), field.NewInt("", "id")
+	over := score.FirstValue().Over(field.WindowSpec{
+		PartitionBy: []field.Expr{field.NewString("", "dept")},
+		OrderBy:     []field.Expr{id},
+		Frame:       &field.FrameSpec{Type: field.FrameRows, Start: field.FrameBound{Type: field.Preceding, Offset: 2}, End: field.FrameBound{Type: field.Following, Offset: 5}},
+	})
+
+	e, ok := field.WindowRawExpr(over).(clause.Expr)
+	if !ok {
+		t.Fatalf("expect clause.Expr, got %T", field.WindowRawExpr(over))
+	}
+	if vars := e.Vars[len(e.Vars)-2:]; !reflect.DeepEqual(vars, []interface{}{2, 5}) {
+		t.Errorf("frame offsets expect to be the last vars [2 5], got %v", e.Vars)
+	}
+	if len(e.Vars) != 5 {
+		t.Errorf("expect 5 vars (function, partition, order, 2 offsets), got %v", e.Vars)
+	}
+}
+
+func TestWindowFunction_MovingAggOverTime_invalid(t *testing.T) {
+	score, ts := field.NewFloat64("", "score"), field.NewTime("", "ts")
+	sum := func(e field.Expr) field.Expr { return e.(field.Field).Sum() }
+
+	if err := score.MovingAggOverTime(sum, 7, field.WindowSpec{OrderBy: []field.Expr{ts}}).CondError(); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+	invalid := []field.WindowSpec{
+		{},
+		{OrderBy: []field.Expr{ts, field.NewInt("", "id")}},
+		{OrderBy: []field.Expr{field.NewInt("", "id")}},
+	}
+	for _, spec := range invalid {
+		if err := score.MovingAggOverTime(sum, 7, spec).CondError(); err == nil {
+			t.Errorf("expect error of invalid spec %+v", spec)
+		}
+	}
+}
+
+func TestWindowFunction_RunningApproxDistinct(t *testing.T) {
+	userID, day := field.NewInt("", "user_id"), field.NewTime("", "day")
+	running := userID.RunningApproxDistinct(field.WindowSpec{PartitionBy: []field.Expr{field.NewString("", "app")}, OrderBy: []field.Expr{day}})
+
+	build := func(name string) *gorm.Statement {
+		db, _ := gorm.Open(namedDialector{name: name}, nil)
+		stmt := &gorm.Statement{DB: db, Clauses: map[string]clause.Clause{}}
+		running.Build(stmt)
+		return stmt
+	}
+
+	stmt := build("postgres")
+	expected := "hll_cardinality(hll_add_agg(hll_hash_any(`user_id`)) OVER (PARTITION BY `app` ORDER BY `day`))"
+	if sql := stmt.SQL.String(); sql != expected {
+		t.Errorf("SQL expects %s got %s", expected, sql)
+	}
+	if stmt.Error != nil {
+		t.Errorf("unexpected error: %s", stmt.Error)
+	}
+
+	if stmt = build("mysql"); stmt.Error == nil {
+		t.Errorf("expect error of unsupported dialect")
+	}
+
+	field.RegisterRunningApproxDistinct("mysql", "APPROX_RUNNING(?) OVER (?)")
+	defer field.RegisterRunningApproxDistinct("mysql", "")
+	stmt = build("mysql")
+	if expected = "APPROX_RUNNING(`user_id`) OVER (PARTITION BY `app` ORDER BY `day`)"; stmt.SQL.String() != expected {
+		t.Errorf("SQL expects %s got %s", expected, stmt.SQL.String())
+	}
+	if stmt.Error != nil {
+		t.Errorf("unexpected error: %s", stmt.Error)
+	}
+}
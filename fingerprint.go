@@ -0,0 +1,31 @@
+package gen
+
+import (
+	"regexp"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+var (
+	fingerprintLiteral = regexp.MustCompile(`'(?:[^']|'')*'|\b\d+(?:\.\d+)?\b`)
+	fingerprintList    = regexp.MustCompile(`\(\s*\?(?:\s*,\s*\?)*\s*\)`)
+	fingerprintSpace   = regexp.MustCompile(`\s+`)
+)
+
+// Fingerprint return normalized SQL of query d without executing it,
+// literals are replaced with placeholders and placeholder lists are collapsed,
+// so queries differing only in args have the same fingerprint
+func Fingerprint(d Dao) (string, error) {
+	stmt := d.underlyingDB().Session(&gorm.Session{DryRun: true}).Find(&[]map[string]interface{}{})
+	if stmt.Error != nil {
+		return "", stmt.Error
+	}
+	return normalizeSQL(stmt.Statement.SQL.String()), nil
+}
+
+func normalizeSQL(sql string) string {
+	sql = fingerprintLiteral.ReplaceAllString(sql, "?")
+	sql = fingerprintList.ReplaceAllString(sql, "(?)")
+	return strings.TrimSpace(fingerprintSpace.ReplaceAllString(sql, " "))
+}
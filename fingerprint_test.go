@@ -0,0 +1,50 @@
+package gen
+
+import "testing"
+
+func TestFingerprint(t *testing.T) {
+	testcases := []struct {
+		A, B   Dao
+		Result string
+	}{
+		{
+			A:      u.DO.Where(u.Age.Gt(18), u.Name.Eq("tom")),
+			B:      u.DO.Where(u.Age.Gt(30), u.Name.Eq("jerry")),
+			Result: "SELECT * FROM `users_info` WHERE `age` > ? AND `name` = ?",
+		},
+		{
+			A:      u.DO.Where(u.ID.In(1, 2)).Limit(10),
+			B:      u.DO.Where(u.ID.In(3, 4, 5)).Limit(20),
+			Result: "SELECT * FROM `users_info` WHERE `id` IN (?) LIMIT ?",
+		},
+		{
+			A:      u.DO.Select(u.Name, u.Score.Sum()).Where(u.Name.Like("a%")).Group(u.Name).Offset(5),
+			B:      u.DO.Select(u.Name, u.Score.Sum()).Where(u.Name.Like("b%")).Group(u.Name).Offset(10),
+			Result: "SELECT `name`,SUM(`score`) FROM `users_info` WHERE `name` LIKE ? GROUP BY `name` OFFSET ?",
+		},
+	}
+
+	for _, testcase := range testcases {
+		a, err := Fingerprint(testcase.A)
+		if err != nil {
+			t.Fatalf("fingerprint fail: %s", err)
+		}
+		b, err := Fingerprint(testcase.B)
+		if err != nil {
+			t.Fatalf("fingerprint fail: %s", err)
+		}
+		if a != b {
+			t.Errorf("fingerprints expect equal, got %s and %s", a, b)
+		}
+		if a != testcase.Result {
+			t.Errorf("fingerprint expects %s got %s", testcase.Result, a)
+		}
+	}
+}
+
+func TestNormalizeSQL(t *testing.T) {
+	sql := "SELECT *  FROM `t1` WHERE `name` = 'it''s' AND `age` > 18.5 AND `id` IN (1, 2,3)"
+	if result := normalizeSQL(sql); result != "SELECT * FROM `t1` WHERE `name` = ? AND `age` > ? AND `id` IN (?)" {
+		t.Errorf("normalized sql got %s", result)
+	}
+}
@@ -2,12 +2,15 @@ package gen
 
 import (
 	"context"
+	"regexp"
+	"strconv"
 	"testing"
 	"time"
 
 	"gorm.io/gorm"
 	"gorm.io/gorm/callbacks"
 	"gorm.io/gorm/clause"
+	"gorm.io/gorm/logger"
 	"gorm.io/gorm/schema"
 	"gorm.io/gorm/utils/tests"
 
@@ -34,6 +37,33 @@ func (mysqlDialectors) Name() string {
 	return "mysql"
 }
 
+type oracleDialectors struct{ tests.DummyDialector }
+
+func (oracleDialectors) Name() string { return "oracle" }
+
+// postgresDialectors binds vars by numbered placeholders $1, $2, ...
+type postgresDialectors struct{ tests.DummyDialector }
+
+func (postgresDialectors) Name() string { return "postgres" }
+
+func (postgresDialectors) BindVarTo(writer clause.Writer, stmt *gorm.Statement, v interface{}) {
+	_, _ = writer.WriteString("$" + strconv.Itoa(len(stmt.Vars)))
+}
+
+func (postgresDialectors) Explain(sql string, vars ...interface{}) string {
+	return logger.ExplainSQL(sql, numericPlaceholder, "'", vars...)
+}
+
+var numericPlaceholder = regexp.MustCompile(`\$(\d+)`)
+
+// namedDialectors binds vars by ? under dialect name, e.g. to check SQL of postgres only methods
+type namedDialectors struct {
+	tests.DummyDialector
+	name string
+}
+
+func (d namedDialectors) Name() string { return d.name }
+
 var db, _ = gorm.Open(mysqlDialectors{}, nil)
 
 func init() {
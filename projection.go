@@ -0,0 +1,63 @@
+package gen
+
+import (
+	"fmt"
+
+	"gorm.io/gorm/clause"
+)
+
+// ProjectedColumns return output column names of sub in order, aliases are used for aliased items.
+// Names are read from the columns passed to Select or Distinct, or the columns of clause.Select,
+// ErrUndeterminableColumns is returned instead of guessing if sub selects *, raw SQL,
+// or an expression without alias
+func ProjectedColumns(sub SubQuery) ([]string, error) {
+	do := sub.underlyingDO()
+	if len(do.selects) > 0 {
+		columns := make([]string, 0, len(do.selects))
+		for i, e := range do.selects {
+			name, ok := projectedName(e.RawExpr())
+			if !ok {
+				return nil, fmt.Errorf("%w: select item %d of %s has no alias", ErrUndeterminableColumns, i+1, do.TableName())
+			}
+			columns = append(columns, name)
+		}
+		return columns, nil
+	}
+
+	if c, ok := do.db.Statement.Clauses[clause.Select{}.Name()]; ok {
+		if sel, ok := c.Expression.(clause.Select); ok && sel.Expression == nil && len(sel.Columns) > 0 {
+			columns := make([]string, 0, len(sel.Columns))
+			for i, column := range sel.Columns {
+				name, ok := projectedName(column)
+				if !ok {
+					return nil, fmt.Errorf("%w: select item %d of %s has no alias", ErrUndeterminableColumns, i+1, do.TableName())
+				}
+				columns = append(columns, name)
+			}
+			return columns, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: subquery of %s does not select columns by field expressions", ErrUndeterminableColumns, do.TableName())
+}
+
+// projectedName return alias of a selected item, or its name if it is a plain column
+func projectedName(item interface{}) (string, bool) {
+	switch item := item.(type) {
+	case clause.Column:
+		if item.Alias != "" {
+			return item.Alias, true
+		}
+		if item.Raw || item.Name == "*" || item.Name == "" {
+			return "", false
+		}
+		return item.Name, true
+	case clause.Expr:
+		// built by field.Expr.As
+		if item.SQL == "? AS ?" && len(item.Vars) == 2 {
+			if alias, ok := item.Vars[1].(clause.Column); ok {
+				return alias.Name, true
+			}
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,66 @@
+package gen
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"gorm.io/gorm/clause"
+
+	"gorm.io/gen/field"
+)
+
+func TestProjectedColumns(t *testing.T) {
+	testcases := []struct {
+		SubQuery SubQuery
+		Columns  []string
+	}{
+		{
+			SubQuery: student.Select(student.ID, student.Name),
+			Columns:  []string{"id", "name"},
+		},
+		{
+			SubQuery: student.Select(student.Name.As("student_name"), student.Age.Sum().As("total_age")),
+			Columns:  []string{"student_name", "total_age"},
+		},
+		{
+			SubQuery: student.Select(student.ID, student.Age.Add(1).As("next_age")),
+			Columns:  []string{"id", "next_age"},
+		},
+		{
+			SubQuery: student.Distinct(student.Instructor, student.Age),
+			Columns:  []string{"instructor", "age"},
+		},
+		{
+			SubQuery: student.Select(field.NewString("t", "name").As("a, b"), field.Func.UnixTimestamp().As("ts")).Where(student.Age.Gt(18)),
+			Columns:  []string{"a, b", "ts"},
+		},
+		{
+			SubQuery: student.DO.getInstance(student.DO.db.Clauses(clause.Select{Columns: []clause.Column{{Name: "id"}, {Name: "name", Alias: "n"}}})),
+			Columns:  []string{"id", "n"},
+		},
+	}
+
+	for _, testcase := range testcases {
+		columns, err := ProjectedColumns(testcase.SubQuery)
+		if err != nil {
+			t.Fatalf("projected columns fail: %s", err)
+		}
+		if !reflect.DeepEqual(columns, testcase.Columns) {
+			t.Errorf("columns expects %v got %v", testcase.Columns, columns)
+		}
+	}
+
+	undeterminable := []SubQuery{
+		student.Select(),
+		student.Select(student.ID).Select(),
+		student.Select(student.ID, student.Age.Sum()),
+		student.DO.getInstance(student.DO.db.Select("id, name AS n")),
+		student.DO.getInstance(student.DO.db.Clauses(clause.Select{Columns: []clause.Column{{Name: "COUNT(*)", Raw: true}}})),
+	}
+	for _, sub := range undeterminable {
+		if columns, err := ProjectedColumns(sub); !errors.Is(err, ErrUndeterminableColumns) {
+			t.Errorf("expect ErrUndeterminableColumns, got %v %v", columns, err)
+		}
+	}
+}
@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"strings"
 
-	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 
 	"gorm.io/gen/field"
@@ -14,6 +13,46 @@ import (
 type WithClause struct {
 	Name  string
 	Query SubQuery
+	// Columns are the output column aliases of the CTE, written as name(a, b)
+	Columns []string
+
+	// Recursive is the recursive term of a WITH RECURSIVE query, joined to Query (the anchor) by UNION ALL
+	Recursive SubQuery
+	// UnionDistinct joins Recursive to Query by UNION, which discards duplicate rows
+	UnionDistinct bool
+
+	search clause.Expression
+	cycle  clause.Expression
+}
+
+// build returns the definition of the CTE: name AS (query)
+func (c WithClause) build() (string, []interface{}) {
+	name, vars := "?", []interface{}{clause.Table{Name: c.Name}}
+	if len(c.Columns) > 0 {
+		name += "(" + strings.TrimSuffix(strings.Repeat("?, ", len(c.Columns)), ", ") + ")"
+		for _, column := range c.Columns {
+			vars = append(vars, clause.Column{Name: column})
+		}
+	}
+
+	sql := name + " AS (?)"
+	vars = append(vars, c.Query.underlyingDB())
+	if c.Recursive != nil {
+		sql = name + " AS (? UNION ALL ?)"
+		if c.UnionDistinct {
+			sql = name + " AS (? UNION ?)"
+		}
+		vars = append(vars, c.Recursive.underlyingDB())
+	}
+	if c.search != nil {
+		sql += " ?"
+		vars = append(vars, c.search)
+	}
+	if c.cycle != nil {
+		sql += " ?"
+		vars = append(vars, c.cycle)
+	}
+	return sql, vars
 }
 
 // WithQuery represents a query that can use WITH clauses
@@ -30,103 +69,171 @@ func (d *DO) With(name string, query SubQuery) *WithQuery {
 	}
 }
 
+// WithCTEColumns creates a new WithQuery with a CTE whose output columns are aliased by columns,
+// e.g. name(a, b) AS (query)
+func (d *DO) WithCTEColumns(name string, columns []string, query SubQuery) *WithQuery {
+	return (&WithQuery{DO: d}).WithCTEColumns(name, columns, query)
+}
+
+// WithRecursive creates a new WithQuery with a recursive CTE,
+// the recursive term is joined to the anchor by UNION ALL
+func (d *DO) WithRecursive(name string, anchor, recursive SubQuery) *WithQuery {
+	return &WithQuery{
+		DO:          d,
+		withClauses: []WithClause{{Name: name, Query: anchor, Recursive: recursive}},
+	}
+}
+
 // With adds another CTE to the existing WithQuery
 func (w *WithQuery) With(name string, query SubQuery) *WithQuery {
 	w.withClauses = append(w.withClauses, WithClause{Name: name, Query: query})
 	return w
 }
 
-// Select executes the final query with all WITH clauses
-func (w *WithQuery) Select(columns ...field.Expr) Dao {
-	// Build the WITH clause SQL
-	var withParts []string
-	var allArgs []interface{}
-	
+// WithCTEColumns adds another CTE whose output columns are aliased by columns,
+// the number of columns is checked against the columns selected by query if they are known
+func (w *WithQuery) WithCTEColumns(name string, columns []string, query SubQuery) *WithQuery {
+	if selected, err := ProjectedColumns(query); err == nil && len(selected) != len(columns) {
+		w.DO = w.DO.withError(fmt.Errorf("CTE %s has %d column aliases, but its query selects %d columns", name, len(columns), len(selected)))
+	}
+	w.withClauses = append(w.withClauses, WithClause{Name: name, Query: query, Columns: columns})
+	return w
+}
+
+// WithRecursive adds another recursive CTE to the existing WithQuery
+func (w *WithQuery) WithRecursive(name string, anchor, recursive SubQuery) *WithQuery {
+	w.withClauses = append(w.withClauses, WithClause{Name: name, Query: anchor, Recursive: recursive})
+	return w
+}
+
+// Union joins the terms of the last recursive CTE by UNION instead of UNION ALL,
+// duplicate rows are discarded, which also stops traversal of cyclic data
+func (w *WithQuery) Union() *WithQuery {
+	return w.setRecursiveOption("UNION", func(c *WithClause) { c.UnionDistinct = true })
+}
+
+// SearchDepthFirst adds SEARCH DEPTH FIRST BY by SET setCol to the last recursive CTE
+func (w *WithQuery) SearchDepthFirst(by field.Expr, setCol string) *WithQuery {
+	return w.setRecursiveOption("SEARCH", func(c *WithClause) {
+		c.search = clause.Expr{
+			SQL:  "SEARCH DEPTH FIRST BY ? SET ?",
+			Vars: []interface{}{clause.Column{Name: by.ColumnName().String()}, clause.Column{Name: setCol}},
+		}
+	})
+}
+
+// Cycle adds CYCLE col SET setCol USING usingCol to the last recursive CTE
+func (w *WithQuery) Cycle(col field.Expr, setCol, usingCol string) *WithQuery {
+	return w.setRecursiveOption("CYCLE", func(c *WithClause) {
+		c.cycle = clause.Expr{
+			SQL:  "CYCLE ? SET ? USING ?",
+			Vars: []interface{}{clause.Column{Name: col.ColumnName().String()}, clause.Column{Name: setCol}, clause.Column{Name: usingCol}},
+		}
+	})
+}
+
+func (w *WithQuery) setRecursiveOption(name string, set func(*WithClause)) *WithQuery {
+	last := len(w.withClauses) - 1
+	if last < 0 || w.withClauses[last].Recursive == nil {
+		w.DO = w.DO.withError(fmt.Errorf("%s clause can only be used with a recursive CTE", name))
+		return w
+	}
+	set(&w.withClauses[last])
+	return w
+}
+
+// buildWithClause builds all CTEs into a single WITH clause
+func (w *WithQuery) buildWithClause() *WithClauseExpr {
+	var (
+		recursive bool
+		parts     = make([]string, 0, len(w.withClauses))
+		args      []interface{}
+	)
 	for _, withClause := range w.withClauses {
-		subDB := withClause.Query.underlyingDB()
-		sql := subDB.ToSQL(func(tx *gorm.DB) *gorm.DB {
-			return tx.Find(&struct{}{})
-		})
-		
-		// Extract SQL and args from the subquery
-		stmt := subDB.Statement
-		if stmt != nil {
-			sql = stmt.SQL.String()
-			allArgs = append(allArgs, stmt.Vars...)
+		if withClause.Recursive != nil {
+			recursive = true
 		}
-		
-		withParts = append(withParts, fmt.Sprintf("%s AS (%s)", withClause.Name, sql))
+		sql, vars := withClause.build()
+		parts = append(parts, sql)
+		args = append(args, vars...)
 	}
-	
-	withSQL := "WITH " + strings.Join(withParts, ", ")
-	
-	// Create a new DO instance with the WITH clause
-	newDB := w.DO.db.Session(&gorm.Session{})
-	
-	// Add the WITH clause as a raw SQL prefix
-	if len(columns) > 0 {
-		selectSQL, selectArgs := buildExpr4Select(newDB.Statement, columns...)
-		finalSQL := fmt.Sprintf("%s SELECT %s", withSQL, selectSQL)
-		allArgs = append(allArgs, selectArgs...)
-		newDB = newDB.Raw(finalSQL, allArgs...)
-	} else {
-		finalSQL := fmt.Sprintf("%s SELECT *", withSQL)
-		newDB = newDB.Raw(finalSQL, allArgs...)
+
+	keyword := "WITH "
+	if recursive {
+		keyword = "WITH RECURSIVE "
 	}
-	
-	return w.DO.getInstance(newDB)
+	return &WithClauseExpr{SQL: keyword + strings.Join(parts, ", "), Args: args}
 }
 
-// From specifies which CTE to select from
+// Select executes the final query with all WITH clauses
+func (w *WithQuery) Select(columns ...field.Expr) Dao {
+	return w.DO.getInstance(w.DO.db.Clauses(w.buildWithClause())).Select(columns...)
+}
+
+// From specifies which CTE to select from, chain Select to project columns of the CTE instead of *
 func (w *WithQuery) From(cteName string) Dao {
-	// Build the WITH clause SQL
-	var withParts []string
-	var allArgs []interface{}
-	
-	for _, withClause := range w.withClauses {
-		subDB := withClause.Query.underlyingDB()
-		sql := subDB.ToSQL(func(tx *gorm.DB) *gorm.DB {
-			return tx.Find(&struct{}{})
-		})
-		
-		// Extract SQL and args from the subquery
-		stmt := subDB.Statement
-		if stmt != nil {
-			sql = stmt.SQL.String()
-			allArgs = append(allArgs, stmt.Vars...)
-		}
-		
-		withParts = append(withParts, fmt.Sprintf("%s AS (%s)", withClause.Name, sql))
+	return w.DO.getInstance(w.DO.db.Clauses(w.buildWithClause()).Table(cteName))
+}
+
+// Diff selects rows of leftCTE whose matching row in rightCTE, joined on keyCols,
+// differs in any of compareCols, NULLs are compared by IS DISTINCT FROM
+func (w *WithQuery) Diff(leftCTE, rightCTE string, keyCols, compareCols []field.Expr) Dao {
+	if len(keyCols) == 0 || len(compareCols) == 0 {
+		return w.DO.withError(ErrEmptyCondition)
 	}
-	
-	withSQL := "WITH " + strings.Join(withParts, ", ")
-	
-	// Create a new DO instance that selects from the specified CTE
-	newDB := w.DO.db.Session(&gorm.Session{})
-	newDB = newDB.Table(cteName)
-	
-	// Add the WITH clause using a custom clause
-	newDB = newDB.Clauses(&WithClauseExpr{SQL: withSQL, Args: allArgs})
-	
-	return w.DO.getInstance(newDB)
+
+	column := func(table string, col field.Expr) clause.Column {
+		return clause.Column{Table: table, Name: col.ColumnName().String()}
+	}
+	on := make([]clause.Expression, 0, len(keyCols))
+	for _, col := range keyCols {
+		on = append(on, clause.Eq{Column: column(leftCTE, col), Value: column(rightCTE, col)})
+	}
+	changed := make([]clause.Expression, 0, len(compareCols))
+	for _, col := range compareCols {
+		changed = append(changed, clause.Expr{SQL: "? IS DISTINCT FROM ?", Vars: []interface{}{column(leftCTE, col), column(rightCTE, col)}})
+	}
+
+	return w.DO.withSelects(w.DO.db.Clauses(
+		w.buildWithClause(),
+		clause.From{
+			Tables: []clause.Table{{Name: leftCTE}},
+			Joins:  []clause.Join{{Type: clause.InnerJoin, Table: clause.Table{Name: rightCTE}, ON: clause.Where{Exprs: on}}},
+		},
+		clause.Where{Exprs: []clause.Expression{clause.Or(changed...)}},
+	).Select("?.*", clause.Table{Name: leftCTE}), nil)
 }
 
-// WithClauseExpr implements clause.Expression for WITH clauses
+// WithClauseExpr implements clause.Expression for WITH clauses,
+// it is written before the SELECT clause of the query
 type WithClauseExpr struct {
 	SQL  string
 	Args []interface{}
 }
 
+// Name implements clause.Interface
+func (w *WithClauseExpr) Name() string { return "SELECT" }
+
 // Build implements clause.Expression
 func (w *WithClauseExpr) Build(builder clause.Builder) {
-	builder.WriteString(w.SQL)
-	builder.AddVar(builder, w.Args...)
+	clause.Expr{SQL: w.SQL, Vars: w.Args}.Build(builder)
 }
 
-// WindowFunction represents a window function expression
+// MergeClause implements clause.Interface
+func (w *WithClauseExpr) MergeClause(c *clause.Clause) {
+	if c.BeforeExpression != nil {
+		c.BeforeExpression = clause.Expr{SQL: "? ?", Vars: []interface{}{w, c.BeforeExpression}}
+		return
+	}
+	c.BeforeExpression = w
+}
+
+// WindowFunction represents a window function expression,
+// use field.WindowFunction for aggregates with FILTER (WHERE ...)
 type WindowFunction struct {
 	Function string
 	overClause *OverClause
+	defaults   *OverClause
 }
 
 // OverClause represents the OVER clause in window functions
@@ -213,6 +320,64 @@ func Min(expr field.Expr) *WindowFunction {
 	return &WindowFunction{Function: fmt.Sprintf("MIN(%s)", exprStr)}
 }
 
+// WindowOption sets a default part of the OVER clause, see WindowFunction.With
+type WindowOption func(*OverClause)
+
+// WindowPartitionBy default PARTITION BY of window functions
+func WindowPartitionBy(exprs ...field.Expr) WindowOption {
+	return func(o *OverClause) { o.PartitionBy(exprs...) }
+}
+
+// WindowOrderBy default ORDER BY of window functions
+func WindowOrderBy(exprs ...field.Expr) WindowOption {
+	return func(o *OverClause) { o.OrderBy(exprs...) }
+}
+
+// WindowRows default ROWS frame of window functions
+func WindowRows(start, end string) WindowOption {
+	return func(o *OverClause) { o.Rows(start, end) }
+}
+
+// WindowRange default RANGE frame of window functions
+func WindowRange(start, end string) WindowOption {
+	return func(o *OverClause) { o.Range(start, end) }
+}
+
+// With applies opts as defaults of the OVER clause, a default is used only if Over does not set the same part,
+// field.WindowFunction takes the same defaults by its With(field.WindowSpec), e.g. a report sharing one partition:
+//
+//	defaults := []gen.WindowOption{gen.WindowPartitionBy(dept)}
+//	gen.Sum(amount).With(defaults...).Over().OrderBy(day)
+func (w *WindowFunction) With(opts ...WindowOption) *WindowFunction {
+	if w.defaults == nil {
+		w.defaults = &OverClause{}
+	}
+	for _, opt := range opts {
+		opt(w.defaults)
+	}
+	return w
+}
+
+// over returns the OVER clause with unset parts taken from defaults
+func (w *WindowFunction) over() *OverClause {
+	if w.defaults == nil {
+		return w.overClause
+	}
+	over := *w.defaults
+	if w.overClause != nil {
+		if len(w.overClause.partitionBy) > 0 {
+			over.partitionBy = w.overClause.partitionBy
+		}
+		if len(w.overClause.orderBy) > 0 {
+			over.orderBy = w.overClause.orderBy
+		}
+		if w.overClause.frame != nil {
+			over.frame = w.overClause.frame
+		}
+	}
+	return &over
+}
+
 // Over specifies the OVER clause for the window function
 func (w *WindowFunction) Over() *OverClause {
 	if w.overClause == nil {
@@ -251,16 +416,30 @@ func (w *WindowFunction) As(alias string) field.Expr {
 	return field.NewExpr(alias, clause.Expr{SQL: sql})
 }
 
+// windowOrderItem renders an ORDER BY item of OVER clause, keeping the direction of
+// expressions built by Asc, Desc or DescNullLast, e.g. ? DESC
+func windowOrderItem(expr field.Expr) string {
+	var direction string
+	if e, ok := expr.RawExpr().(clause.Expr); ok && strings.HasPrefix(e.SQL, "? ") {
+		direction = e.SQL[1:]
+	}
+	if columnName, ok := expr.(field.IColumnName); ok {
+		return string(columnName.ColumnName()) + direction
+	}
+	return fmt.Sprintf("%s", expr.RawExpr())
+}
+
 // buildSQL builds the complete window function SQL
 func (w *WindowFunction) buildSQL() string {
 	sql := w.Function + " OVER ("
+	over := w.over()
 	
-	if w.overClause != nil {
+	if over != nil {
 		var parts []string
 		
-		if len(w.overClause.partitionBy) > 0 {
+		if len(over.partitionBy) > 0 {
 			var partitions []string
-			for _, expr := range w.overClause.partitionBy {
+			for _, expr := range over.partitionBy {
 				if columnName, ok := expr.(field.IColumnName); ok {
 					partitions = append(partitions, string(columnName.ColumnName()))
 				} else {
@@ -270,24 +449,20 @@ func (w *WindowFunction) buildSQL() string {
 			parts = append(parts, "PARTITION BY "+strings.Join(partitions, ", "))
 		}
 		
-		if len(w.overClause.orderBy) > 0 {
+		if len(over.orderBy) > 0 {
 			var orders []string
-			for _, expr := range w.overClause.orderBy {
-				if columnName, ok := expr.(field.IColumnName); ok {
-					orders = append(orders, string(columnName.ColumnName()))
-				} else {
-					orders = append(orders, fmt.Sprintf("%s", expr.RawExpr()))
-				}
+			for _, expr := range over.orderBy {
+				orders = append(orders, windowOrderItem(expr))
 			}
 			parts = append(parts, "ORDER BY "+strings.Join(orders, ", "))
 		}
 		
-		if w.overClause.frame != nil {
-			frameSQL := w.overClause.frame.Type
-			if w.overClause.frame.End != "" {
-				frameSQL += fmt.Sprintf(" BETWEEN %s AND %s", w.overClause.frame.Start, w.overClause.frame.End)
+		if over.frame != nil {
+			frameSQL := over.frame.Type
+			if over.frame.End != "" {
+				frameSQL += fmt.Sprintf(" BETWEEN %s AND %s", over.frame.Start, over.frame.End)
 			} else {
-				frameSQL += " " + w.overClause.frame.Start
+				frameSQL += " " + over.frame.Start
 			}
 			parts = append(parts, frameSQL)
 		}
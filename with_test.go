@@ -1,9 +1,14 @@
 package gen
 
 import (
+	"errors"
+	"reflect"
+	"strings"
 	"testing"
 
+	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
+
 	"gorm.io/gen/field"
 )
 
@@ -30,12 +35,12 @@ func TestWindowFunction(t *testing.T) {
 func TestWindowFunctionWithOver(t *testing.T) {
 	// Create a mock field expression
 	mockField := field.NewExpr("test_field", clause.Expr{SQL: "test_field"})
-	
+
 	// Test window function with OVER clause
 	wf := RowNumber()
 	over := wf.Over()
 	over.PartitionBy(mockField).OrderBy(mockField)
-	
+
 	sql := wf.buildSQL()
 	expected := "ROW_NUMBER() OVER (PARTITION BY test_field ORDER BY test_field)"
 	if sql != expected {
@@ -46,12 +51,12 @@ func TestWindowFunctionWithOver(t *testing.T) {
 func TestWindowFunctionWithFrame(t *testing.T) {
 	// Create a mock field expression
 	mockField := field.NewExpr("test_field", clause.Expr{SQL: "test_field"})
-	
+
 	// Test window function with frame specification
 	wf := Sum(mockField)
 	over := wf.Over()
 	over.PartitionBy(mockField).OrderBy(mockField).Rows("UNBOUNDED PRECEDING", "CURRENT ROW")
-	
+
 	sql := wf.buildSQL()
 	expected := "SUM(test_field) OVER (PARTITION BY test_field ORDER BY test_field ROWS BETWEEN UNBOUNDED PRECEDING AND CURRENT ROW)"
 	if sql != expected {
@@ -59,34 +64,70 @@ func TestWindowFunctionWithFrame(t *testing.T) {
 	}
 }
 
+func TestWindowFunctionMixedOrder(t *testing.T) {
+	wf := RowNumber()
+	wf.Over().OrderBy(field.NewInt("", "a").Asc(), field.NewInt("", "b").Desc())
+
+	sql := wf.buildSQL()
+	expected := "ROW_NUMBER() OVER (ORDER BY a ASC, b DESC)"
+	if sql != expected {
+		t.Errorf("Expected %s, got %s", expected, sql)
+	}
+}
+
+func TestWindowFunctionDefaults(t *testing.T) {
+	dept, day := field.NewString("", "dept"), field.NewTime("", "day")
+	defaults := []WindowOption{WindowPartitionBy(dept), WindowRows("UNBOUNDED PRECEDING", "CURRENT ROW")}
+
+	wf := Sum(field.NewFloat64("", "amount")).With(defaults...)
+	wf.Over().OrderBy(day)
+	expected := "SUM(amount) OVER (PARTITION BY dept ORDER BY day ROWS BETWEEN UNBOUNDED PRECEDING AND CURRENT ROW)"
+	if sql := wf.buildSQL(); sql != expected {
+		t.Errorf("Expected %s, got %s", expected, sql)
+	}
+
+	own := RowNumber()
+	own.Over().PartitionBy(field.NewString("", "region")).OrderBy(day)
+	own.With(defaults...)
+	expected = "ROW_NUMBER() OVER (PARTITION BY region ORDER BY day ROWS BETWEEN UNBOUNDED PRECEDING AND CURRENT ROW)"
+	if sql := own.buildSQL(); sql != expected {
+		t.Errorf("Expected %s, got %s", expected, sql)
+	}
+
+	expected = "RANK() OVER (PARTITION BY dept ROWS BETWEEN UNBOUNDED PRECEDING AND CURRENT ROW)"
+	if sql := Rank().With(defaults...).buildSQL(); sql != expected {
+		t.Errorf("Expected %s, got %s", expected, sql)
+	}
+}
+
 func TestAggregateWindowFunctions(t *testing.T) {
 	// Create a mock field expression
 	mockField := field.NewExpr("amount", clause.Expr{SQL: "amount"})
-	
+
 	// Test COUNT window function
 	count := Count(mockField)
 	if count.Function != "COUNT(amount)" {
 		t.Errorf("Expected COUNT(amount), got %s", count.Function)
 	}
-	
+
 	// Test SUM window function
 	sum := Sum(mockField)
 	if sum.Function != "SUM(amount)" {
 		t.Errorf("Expected SUM(amount), got %s", sum.Function)
 	}
-	
+
 	// Test AVG window function
 	avg := Avg(mockField)
 	if avg.Function != "AVG(amount)" {
 		t.Errorf("Expected AVG(amount), got %s", avg.Function)
 	}
-	
+
 	// Test MAX window function
 	max := Max(mockField)
 	if max.Function != "MAX(amount)" {
 		t.Errorf("Expected MAX(amount), got %s", max.Function)
 	}
-	
+
 	// Test MIN window function
 	min := Min(mockField)
 	if min.Function != "MIN(amount)" {
@@ -100,15 +141,15 @@ func TestWithClauseExpr(t *testing.T) {
 		SQL:  "WITH test_cte AS (SELECT * FROM test_table)",
 		Args: []interface{}{"arg1", "arg2"},
 	}
-	
+
 	// Mock builder for testing
 	mockBuilder := &mockClauseBuilder{}
 	withExpr.Build(mockBuilder)
-	
+
 	if mockBuilder.sql != "WITH test_cte AS (SELECT * FROM test_table)" {
 		t.Errorf("Expected WITH clause SQL, got %s", mockBuilder.sql)
 	}
-	
+
 	if len(mockBuilder.vars) != 2 {
 		t.Errorf("Expected 2 variables, got %d", len(mockBuilder.vars))
 	}
@@ -148,24 +189,24 @@ func TestFrameClause(t *testing.T) {
 	wf := Sum(mockField)
 	over := wf.Over()
 	over.Rows("2 PRECEDING", "2 FOLLOWING")
-	
+
 	if over.frame.Type != "ROWS" {
 		t.Errorf("Expected ROWS frame type, got %s", over.frame.Type)
 	}
-	
+
 	if over.frame.Start != "2 PRECEDING" {
 		t.Errorf("Expected '2 PRECEDING' start, got %s", over.frame.Start)
 	}
-	
+
 	if over.frame.End != "2 FOLLOWING" {
 		t.Errorf("Expected '2 FOLLOWING' end, got %s", over.frame.End)
 	}
-	
+
 	// Test RANGE frame
 	wf2 := Avg(mockField)
 	over2 := wf2.Over()
 	over2.Range("UNBOUNDED PRECEDING", "CURRENT ROW")
-	
+
 	if over2.frame.Type != "RANGE" {
 		t.Errorf("Expected RANGE frame type, got %s", over2.frame.Type)
 	}
@@ -176,14 +217,157 @@ func TestComplexWindowFunction(t *testing.T) {
 	mockField1 := field.NewExpr("unionid", clause.Expr{SQL: "unionid"})
 	mockField2 := field.NewExpr("platform_name", clause.Expr{SQL: "platform_name"})
 	mockField3 := field.NewExpr("created_at", clause.Expr{SQL: "created_at"})
-	
+
 	wf := RowNumber()
 	over := wf.Over()
 	over.PartitionBy(mockField1, mockField2).OrderBy(mockField3)
-	
+
 	sql := wf.buildSQL()
 	expected := "ROW_NUMBER() OVER (PARTITION BY unionid, platform_name ORDER BY created_at)"
 	if sql != expected {
 		t.Errorf("Expected %s, got %s", expected, sql)
 	}
-} 
\ No newline at end of file
+}
+
+func buildWithQuery(q Dao) (string, []interface{}) {
+	stmt := q.underlyingDB().Find(&[]map[string]interface{}{}).Statement
+	return stmt.SQL.String(), stmt.Vars
+}
+
+func TestWithQuery_From(t *testing.T) {
+	s := student.Select(student.ID, student.Name).Where(student.Age.Gt(18))
+	sql, vars := buildWithQuery(student.With("adult", s).From("adult"))
+
+	expected := "WITH `adult` AS (SELECT `student`.`id`,`student`.`name` FROM `student` WHERE `student`.`age` > ?) SELECT * FROM `adult`"
+	if sql != expected {
+		t.Errorf("SQL expects: %s\ngot: %s", expected, sql)
+	}
+	if len(vars) != 1 || vars[0] != 18 {
+		t.Errorf("Vars expects: [18], got: %v", vars)
+	}
+}
+
+func TestWithQuery_FromSelect(t *testing.T) {
+	s := student.Select(student.ID, student.Name, student.Age).Where(student.Age.Gt(18))
+	q := student.With("adult", s).From("adult").Select(field.NewInt("adult", "id"), field.NewString("adult", "name"))
+	sql, _ := buildWithQuery(q)
+
+	expected := "WITH `adult` AS (SELECT `student`.`id`,`student`.`name`,`student`.`age` FROM `student` WHERE `student`.`age` > ?) SELECT `adult`.`id`,`adult`.`name` FROM `adult`"
+	if sql != expected {
+		t.Errorf("SQL expects: %s\ngot: %s", expected, sql)
+	}
+}
+
+func TestWithQuery_CTEColumns(t *testing.T) {
+	s := student.Select(student.ID, student.Name).Where(student.Age.Gt(18))
+	sql, vars := buildWithQuery(student.WithCTEColumns("adult", []string{"a", "b"}, s).From("adult"))
+
+	expected := "WITH `adult`(`a`, `b`) AS (SELECT `student`.`id`,`student`.`name` FROM `student` WHERE `student`.`age` > ?) SELECT * FROM `adult`"
+	if sql != expected {
+		t.Errorf("SQL expects: %s\ngot: %s", expected, sql)
+	}
+	if len(vars) != 1 || vars[0] != 18 {
+		t.Errorf("Vars expects: [18], got: %v", vars)
+	}
+
+	err := student.With("all", student.Select()).WithCTEColumns("adult", []string{"a"}, s).From("adult").underlyingDB().Error
+	if err == nil || !strings.Contains(err.Error(), "1 column aliases") {
+		t.Errorf("expect column count mismatch error, got %v", err)
+	}
+	if err := student.WithCTEColumns("all", []string{"a"}, student.Select()).From("all").underlyingDB().Error; err != nil {
+		t.Errorf("expect unknown column count not checked, got %v", err)
+	}
+
+	withVars := student.Select(student.ID, student.Age.Add(1).As("next_age")).Where(student.Age.Gt(18))
+	err = student.WithCTEColumns("adult", []string{"a"}, withVars).From("adult").underlyingDB().Error
+	if err == nil || !strings.Contains(err.Error(), "but its query selects 2 columns") {
+		t.Errorf("expect column count mismatch error for select with bind vars, got %v", err)
+	}
+}
+
+func TestWithQuery_numberedPlaceholders(t *testing.T) {
+	pgDB, _ := gorm.Open(postgresDialectors{}, &gorm.Config{DryRun: true})
+
+	var do DO
+	do.UseDB(pgDB)
+	do.UseModel(StudentRaw{})
+
+	adultAge := field.NewInt("adult", "age")
+	q := do.With("adult", do.Select(student.ID, student.Age).Where(student.Age.Gt(18))).From("adult").Where(adultAge.Lt(30))
+	sql, vars := buildWithQuery(q)
+
+	expected := "WITH `adult` AS (SELECT `student`.`id`,`student`.`age` FROM `student` WHERE `student`.`age` > $1) SELECT * FROM `adult` WHERE `adult`.`age` < $2"
+	if sql != expected {
+		t.Errorf("SQL expects: %s\ngot: %s", expected, sql)
+	}
+	if expectedVars := []interface{}{18, 30}; !reflect.DeepEqual(vars, expectedVars) {
+		t.Errorf("Vars expects: %v got: %v", expectedVars, vars)
+	}
+}
+
+func TestWithQuery_recursive(t *testing.T) {
+	anchor := student.Select(student.ID, student.Instructor).Where(student.Instructor.Eq(0))
+	recursive := student.Select(student.ID, student.Instructor).Where(student.Instructor.Gt(0))
+
+	sql, vars := buildWithQuery(student.WithRecursive("tree", anchor, recursive).
+		SearchDepthFirst(student.ID, "ordercol").
+		Cycle(student.ID, "is_cycle", "path").
+		From("tree"))
+
+	expected := "WITH RECURSIVE `tree` AS (SELECT `student`.`id`,`student`.`instructor` FROM `student` WHERE `student`.`instructor` = ? " +
+		"UNION ALL SELECT `student`.`id`,`student`.`instructor` FROM `student` WHERE `student`.`instructor` > ?) " +
+		"SEARCH DEPTH FIRST BY `id` SET `ordercol` CYCLE `id` SET `is_cycle` USING `path` SELECT * FROM `tree`"
+	if sql != expected {
+		t.Errorf("SQL expects: %s\ngot: %s", expected, sql)
+	}
+	if len(vars) != 2 || vars[0] != int64(0) || vars[1] != int64(0) {
+		t.Errorf("Vars expects: [0 0], got: %v", vars)
+	}
+}
+
+func TestWithQuery_recursiveUnion(t *testing.T) {
+	anchor := student.Select(student.ID, student.Instructor).Where(student.ID.Eq(1))
+	tree := field.NewInt64("tree", "id")
+	recursive := student.Select(student.ID, student.Instructor).Where(student.Instructor.EqCol(tree))
+
+	sql, _ := buildWithQuery(student.WithRecursive("tree", anchor, recursive).Union().From("tree"))
+
+	prefix := "WITH RECURSIVE `tree` AS (SELECT `student`.`id`,`student`.`instructor` FROM `student` WHERE `student`.`id` = ? UNION SELECT"
+	if !strings.HasPrefix(sql, prefix) {
+		t.Errorf("SQL expects prefix: %s\ngot: %s", prefix, sql)
+	}
+	if strings.Contains(sql, "UNION ALL") {
+		t.Errorf("SQL expects UNION, got: %s", sql)
+	}
+
+	if err := student.With("s", anchor).Union().From("s").underlyingDB().Error; err == nil {
+		t.Error("UNION on a non-recursive CTE expects an error")
+	}
+}
+
+func TestWithQuery_searchRequiresRecursive(t *testing.T) {
+	q := student.With("s", student.Select(student.ID)).SearchDepthFirst(student.ID, "ordercol").From("s")
+	if err := q.underlyingDB().Error; err == nil {
+		t.Error("SEARCH on a non-recursive CTE expects an error")
+	}
+}
+
+func TestWithQuery_Diff(t *testing.T) {
+	source := student.Select(student.ID, student.Name, student.Age)
+	target := student.Select(student.ID, student.Name, student.Age).Where(student.Age.Gt(18))
+	q := student.With("src", source).With("dst", target).
+		Diff("src", "dst", []field.Expr{student.ID}, []field.Expr{student.Name, student.Age})
+	sql, _ := buildWithQuery(q)
+
+	expected := "WITH `src` AS (SELECT `student`.`id`,`student`.`name`,`student`.`age` FROM `student`), " +
+		"`dst` AS (SELECT `student`.`id`,`student`.`name`,`student`.`age` FROM `student` WHERE `student`.`age` > ?) " +
+		"SELECT `src`.* FROM `src` INNER JOIN `dst` ON `src`.`id` = `dst`.`id` " +
+		"WHERE (`src`.`name` IS DISTINCT FROM `dst`.`name` OR `src`.`age` IS DISTINCT FROM `dst`.`age`)"
+	if sql != expected {
+		t.Errorf("SQL expects: %s\ngot: %s", expected, sql)
+	}
+
+	if err := student.With("src", source).Diff("src", "dst", nil, []field.Expr{student.Name}).underlyingDB().Error; !errors.Is(err, ErrEmptyCondition) {
+		t.Errorf("expect ErrEmptyCondition, got %v", err)
+	}
+}